@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/th-lange/glox/interpreter"
+)
+
+var outputFile string
+
+var compileCmd = &cobra.Command{
+	Use:   "compile <file>",
+	Short: "Compiles a lox file to bytecode",
+	Long: `This compiles the file for the bytecode vm and writes the result to a .gloxc file.
+The file can be passed to glox like any lox file, it is run without scanning and parsing.
+Without --output the name of the lox file is used with the .gloxc extension.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := outputFile
+		if output == "" {
+			output = strings.TrimSuffix(args[0], ".lox") + interpreter.BYTECODE_EXTENSION
+		}
+		intpr := interpreter.Init(Debug)
+		intpr.Optimize = Optimize
+		intpr.CompileFile(args[0], output)
+	},
+}
+
+func init() {
+	compileCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Target file of the bytecode")
+	rootCmd.AddCommand(compileCmd)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/th-lange/glox/interpreter"
+)
+
+var disasmCmd = &cobra.Command{
+	Use:   "disasm <file>...",
+	Short: "Prints the bytecode of lox files",
+	Long: `This compiles the files for the bytecode vm without running them, .gloxc files are loaded instead.
+Every instruction is printed with its offset, source line, opcode and operands.
+Functions declared in the file follow after the top level code.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		intpr := interpreter.Init(Debug)
+		intpr.Optimize = Optimize
+		intpr.DisassembleFiles(args...)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(disasmCmd)
+}
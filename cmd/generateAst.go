@@ -9,19 +9,21 @@ import (
 )
 
 var packageName string
+var stmtPackageName string
 
 var generateAstCmd = &cobra.Command{
 	Use:   "generateAst",
 	Short: "Generates the Parser AST",
 	Long: `This creates the AST files, needed by the parser.
-It will setup the files in the "expressions" folder. Any previous files will be overwritten!`,
+It will setup the files in the "expression" and "statement" folders. Any previous files will be overwritten!`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("createAst called. Target: " + base.HomeDir)
-		util.GenerateAst(base.HomeDir, packageName)
+		util.GenerateAst(base.HomeDir, packageName, stmtPackageName)
 	},
 }
 
 func init() {
 	generateAstCmd.Flags().StringVarP(&packageName, "target", "t", "expression", "Target Path of the ast")
+	generateAstCmd.Flags().StringVarP(&stmtPackageName, "stmtTarget", "s", "statement", "Target Path of the statement ast")
 	rootCmd.AddCommand(generateAstCmd)
 }
@@ -9,13 +9,29 @@ import (
 )
 
 var Debug int8
+var Backend string
+var GCStress bool
+var Optimize bool
 
 var rootCmd = &cobra.Command{
 	Use:   "glox",
 	Short: "g-lox is a interpreter written in go",
+	Long: `g-lox is a interpreter written in go
+
+Runs the given lox files, "-" reads the script from stdin and runs it while reading.
+Without files, a prompt is started.`,
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		intpr := interpreter.Init(Debug)
+		intpr.Optimize = Optimize
+		intpr.BackendOptions = interpreter.BackendOptions{Debug: Debug, GCStress: GCStress}
+		backend, err := interpreter.NewBackend(Backend, os.Stdout, intpr.BackendOptions)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		intpr.Backend = backend
 		if len(args) == 0 {
 			intpr.RunPrompt()
 		} else {
@@ -26,6 +42,9 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	rootCmd.PersistentFlags().Int8VarP(&Debug, "debug", "d", 0, "Debugging level and verbosity")
+	rootCmd.PersistentFlags().BoolVarP(&Optimize, "optimize", "O", false, "Fold constants and remove dead code before running or compiling")
+	rootCmd.Flags().BoolVar(&GCStress, "gc-stress", false, "Run the garbage collector of the vm on every allocation, needs --backend vm")
+	rootCmd.Flags().StringVarP(&Backend, "backend", "b", interpreter.TREE_WALKER, "Execution engine: 'tree' walks the syntax tree, 'vm' compiles to bytecode")
 
 }
 
@@ -0,0 +1,211 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+)
+
+// A bytecode file starts with a fixed size header, followed by the encoded script function:
+//
+//	magic "GLXC" | version uint16 | payload length uint32 | crc32 of the payload uint32 | payload
+//
+// All integers in the header are big endian, the payload uses varints.
+// The version has to be increased with every change of the instruction set or the payload layout.
+const (
+	BYTECODE_MAGIC   = "GLXC"
+	BYTECODE_VERSION = 3
+	headerSize       = len(BYTECODE_MAGIC) + 2 + 4 + 4
+)
+
+const (
+	numberConstant byte = iota
+	stringConstant
+	functionConstant
+)
+
+// Indicates that a bytecode file can't be loaded
+type FormatError struct {
+	Message string
+}
+
+func (fe FormatError) Error() string {
+	return "FormatError: " + fe.Message
+}
+
+// WriteBytecode serializes the compiled script including all nested functions.
+func WriteBytecode(out io.Writer, function *Function) error {
+	payload := bytes.Buffer{}
+	encodeFunction(&payload, function)
+
+	header := make([]byte, headerSize)
+	copy(header, BYTECODE_MAGIC)
+	binary.BigEndian.PutUint16(header[4:6], BYTECODE_VERSION)
+	binary.BigEndian.PutUint32(header[6:10], uint32(payload.Len()))
+	binary.BigEndian.PutUint32(header[10:14], crc32.ChecksumIEEE(payload.Bytes()))
+
+	if _, err := out.Write(header); err != nil {
+		return err
+	}
+	_, err := out.Write(payload.Bytes())
+	return err
+}
+
+// ReadBytecode loads a script written by WriteBytecode.
+// The header and checksum are validated before the payload is decoded.
+func ReadBytecode(in io.Reader) (*Function, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(BYTECODE_MAGIC) || string(data[:len(BYTECODE_MAGIC)]) != BYTECODE_MAGIC {
+		return nil, FormatError{"Not a glox bytecode file."}
+	}
+	if len(data) < headerSize {
+		return nil, FormatError{"File is truncated, the header is incomplete."}
+	}
+
+	version := binary.BigEndian.Uint16(data[4:6])
+	if version != BYTECODE_VERSION {
+		return nil, FormatError{"Unsupported bytecode version " + strconv.Itoa(int(version)) + ", expected version " + strconv.Itoa(BYTECODE_VERSION) + ". Please recompile the script."}
+	}
+	length := int(binary.BigEndian.Uint32(data[6:10]))
+	payload := data[headerSize:]
+	if len(payload) < length {
+		return nil, FormatError{"File is truncated, expected " + strconv.Itoa(length-len(payload)) + " more bytes."}
+	}
+	if len(payload) > length {
+		return nil, FormatError{"Unexpected data after the end of the bytecode."}
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(data[10:14]) {
+		return nil, FormatError{"Checksum mismatch, the file is corrupted."}
+	}
+
+	return decodePayload(payload)
+}
+
+func decodePayload(payload []byte) (function *Function, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			formatError, ok := r.(FormatError)
+			if !ok {
+				panic(r)
+			}
+			function, err = nil, formatError
+		}
+	}()
+
+	dec := &decoder{data: payload}
+	function = dec.readFunction()
+	if dec.offset != len(payload) {
+		panic(FormatError{"Unexpected data after the end of the bytecode."})
+	}
+	verifyScript(function)
+	return function, nil
+}
+
+func encodeFunction(out *bytes.Buffer, function *Function) {
+	encodeString(out, function.Name)
+	encodeInt(out, function.Arity)
+	encodeInt(out, function.UpvalueCount)
+
+	encodeInt(out, len(function.Chunk.Code))
+	out.Write(function.Chunk.Code)
+
+	encodeInt(out, len(function.Chunk.Lines))
+	for _, start := range function.Chunk.Lines {
+		encodeInt(out, start.Offset)
+		encodeInt(out, start.Line)
+		encodeInt(out, start.Column)
+		encodeInt(out, start.Position)
+		encodeInt(out, start.Length)
+	}
+
+	encodeInt(out, len(function.Chunk.Constants))
+	for _, constant := range function.Chunk.Constants {
+		switch value := constant.(type) {
+		case float64:
+			out.WriteByte(numberConstant)
+			bits := make([]byte, 8)
+			binary.BigEndian.PutUint64(bits, math.Float64bits(value))
+			out.Write(bits)
+		case string:
+			out.WriteByte(stringConstant)
+			encodeString(out, value)
+		case *Function:
+			out.WriteByte(functionConstant)
+			encodeFunction(out, value)
+		}
+	}
+}
+
+func encodeInt(out *bytes.Buffer, value int) {
+	varint := make([]byte, binary.MaxVarintLen64)
+	out.Write(varint[:binary.PutUvarint(varint, uint64(value))])
+}
+
+func encodeString(out *bytes.Buffer, value string) {
+	encodeInt(out, len(value))
+	out.WriteString(value)
+}
+
+// decoder reads the payload, running out of data is reported as FormatError panic
+type decoder struct {
+	data   []byte
+	offset int
+}
+
+func (dec *decoder) readBytes(count int) []byte {
+	if count < 0 || dec.offset+count > len(dec.data) {
+		panic(FormatError{"Bytecode ends unexpectedly."})
+	}
+	dec.offset += count
+	return dec.data[dec.offset-count : dec.offset]
+}
+
+func (dec *decoder) readInt() int {
+	value, size := binary.Uvarint(dec.data[dec.offset:])
+	if size <= 0 || value > math.MaxInt32 {
+		panic(FormatError{"Bytecode ends unexpectedly."})
+	}
+	dec.offset += size
+	return int(value)
+}
+
+func (dec *decoder) readString() string {
+	return string(dec.readBytes(dec.readInt()))
+}
+
+func (dec *decoder) readFunction() *Function {
+	function := &Function{
+		Name:         dec.readString(),
+		Arity:        dec.readInt(),
+		UpvalueCount: dec.readInt(),
+	}
+
+	function.Chunk.Code = append([]byte(nil), dec.readBytes(dec.readInt())...)
+
+	for count := dec.readInt(); count > 0; count-- {
+		start := LineStart{Offset: dec.readInt(), Line: dec.readInt(), Column: dec.readInt(), Position: dec.readInt(), Length: dec.readInt()}
+		function.Chunk.Lines = append(function.Chunk.Lines, start)
+	}
+
+	for count := dec.readInt(); count > 0; count-- {
+		switch tag := dec.readBytes(1)[0]; tag {
+		case numberConstant:
+			bits := binary.BigEndian.Uint64(dec.readBytes(8))
+			function.Chunk.Constants = append(function.Chunk.Constants, math.Float64frombits(bits))
+		case stringConstant:
+			function.Chunk.Constants = append(function.Chunk.Constants, dec.readString())
+		case functionConstant:
+			function.Chunk.Constants = append(function.Chunk.Constants, dec.readFunction())
+		default:
+			panic(FormatError{"Unknown constant type " + strconv.Itoa(int(tag)) + "."})
+		}
+	}
+	return function
+}
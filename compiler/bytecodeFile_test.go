@@ -0,0 +1,129 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestBytecode(t *testing.T) (*Function, []byte) {
+	function, err := Compile(parseProgram(t, `fun outer(a) {
+  fun inner() { return a + 0.5; }
+  return inner;
+}
+class Greeter { greet(name) { print "Hi " + name; } }
+print outer(1)();`))
+	assert.NoError(t, err)
+
+	out := bytes.Buffer{}
+	assert.NoError(t, WriteBytecode(&out, function))
+	return function, out.Bytes()
+}
+
+func TestWriteBytecode_Header(t *testing.T) {
+	_, data := writeTestBytecode(t)
+
+	assert.Equal(t, BYTECODE_MAGIC, string(data[:4]))
+	assert.Equal(t, uint16(BYTECODE_VERSION), binary.BigEndian.Uint16(data[4:6]))
+	assert.Equal(t, uint32(len(data)-headerSize), binary.BigEndian.Uint32(data[6:10]), "Expecting the payload length in the header.")
+}
+
+func TestReadBytecode(t *testing.T) {
+	function, data := writeTestBytecode(t)
+
+	loaded, err := ReadBytecode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, function, loaded, "Expecting the functions, constants and line tables to survive the round trip.")
+}
+
+func TestReadBytecode_Invalid(t *testing.T) {
+	_, data := writeTestBytecode(t)
+
+	withVersion := append([]byte(nil), data...)
+	binary.BigEndian.PutUint16(withVersion[4:6], BYTECODE_VERSION+1)
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-1] ^= 0xff
+
+	tests := []struct {
+		name    string
+		data    []byte
+		message string
+	}{
+		{"Empty", []byte{}, "Not a glox bytecode file."},
+		{"Lox source", []byte("print 1;"), "Not a glox bytecode file."},
+		{"Truncated header", data[:8], "File is truncated, the header is incomplete."},
+		{"Truncated payload", data[:len(data)-3], "File is truncated, expected 3 more bytes."},
+		{"Trailing data", append(append([]byte(nil), data...), 0), "Unexpected data after the end of the bytecode."},
+		{"Version mismatch", withVersion, "Unsupported bytecode version 4, expected version 3. Please recompile the script."},
+		{"Checksum mismatch", corrupted, "Checksum mismatch, the file is corrupted."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			function, err := ReadBytecode(bytes.NewReader(tt.data))
+			assert.Nil(t, function)
+			assert.EqualError(t, err, "FormatError: "+tt.message)
+		})
+	}
+}
+
+func TestReadBytecode_CorruptedCode(t *testing.T) {
+	lines := []LineStart{{Offset: 0, Line: 1}}
+	closure := &Function{Name: "inner", UpvalueCount: 1, Chunk: Chunk{Code: []byte{byte(OP_NIL), byte(OP_RETURN)}, Lines: lines}}
+
+	tests := []struct {
+		name      string
+		code      []byte
+		constants []interface{}
+		lines     []LineStart
+		message   string
+	}{
+		{"Unknown opcode", []byte{byte(OP_METHOD) + 1}, nil, lines, "Unknown opcode 40. (<script>, offset 0)"},
+		{"Constant out of range", []byte{byte(OP_CONSTANT), 7, 0, byte(OP_RETURN)}, []interface{}{1.0}, lines, "Constant index 1792 is out of range. (<script>, offset 0)"},
+		{"Truncated operand", []byte{byte(OP_NIL), byte(OP_CONSTANT), 0}, []interface{}{1.0}, lines, "The instruction is truncated. (<script>, offset 1)"},
+		{"Name is a number", []byte{byte(OP_GET_GLOBAL), 0, 0, byte(OP_RETURN)}, []interface{}{1.0}, lines, "The constant has to be a name. (<script>, offset 0)"},
+		{"Closure of a string", []byte{byte(OP_CLOSURE), 0, 0, byte(OP_RETURN)}, []interface{}{"inner"}, lines, "The constant has to be a function. (<script>, offset 0)"},
+		{"Upvalue out of range", []byte{byte(OP_CLOSURE), 0, 0, 0, 0, byte(OP_RETURN)}, []interface{}{closure}, lines, "Upvalue 0 is out of range. (<script>, offset 0)"},
+		{"Local out of range", []byte{byte(OP_GET_LOCAL), 1, byte(OP_RETURN)}, nil, lines, "Local slot 1 is out of range. (<script>, offset 0)"},
+		{"Stack underflow", []byte{byte(OP_POP), byte(OP_NIL), byte(OP_RETURN)}, nil, lines, "Stack underflow. (<script>, offset 0)"},
+		{"Jump out of the code", []byte{byte(OP_JUMP), 0, 9, byte(OP_NIL), byte(OP_RETURN)}, nil, lines, "Jump out of the code. (<script>, offset 12)"},
+		{"Inconsistent stack depth", []byte{byte(OP_TRUE), byte(OP_JUMP_IF_FALSE), 0, 1, byte(OP_NIL), byte(OP_RETURN)}, nil, lines, "Inconsistent stack depth. (<script>, offset 5)"},
+		{"Missing return", []byte{byte(OP_NIL)}, nil, lines, "The code ends without return. (<script>, offset 0)"},
+		{"Empty line table", []byte{byte(OP_NIL), byte(OP_RETURN)}, nil, nil, "The line table doesn't start with the code. (<script>, offset 0)"},
+		{"Line table past the code", []byte{byte(OP_NIL), byte(OP_RETURN)}, nil, []LineStart{{Offset: 0, Line: 1}, {Offset: 2, Line: 2}}, "The line table doesn't match the code. (<script>, offset 2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			assert.NoError(t, WriteBytecode(&out, &Function{Chunk: Chunk{Code: tt.code, Constants: tt.constants, Lines: tt.lines}}))
+
+			function, err := ReadBytecode(bytes.NewReader(out.Bytes()))
+			assert.Nil(t, function)
+			assert.EqualError(t, err, "FormatError: "+tt.message)
+		})
+	}
+}
+
+func TestReadBytecode_CorruptedPayload(t *testing.T) {
+	_, data := writeTestBytecode(t)
+
+	// every single byte is damaged and the checksum fixed, so only the decoding and the verification can notice
+	for offset := headerSize; offset < len(data); offset++ {
+		for _, mask := range []byte{0x01, 0x80, 0xff} {
+			corrupted := append([]byte(nil), data...)
+			corrupted[offset] ^= mask
+			binary.BigEndian.PutUint32(corrupted[10:14], crc32.ChecksumIEEE(corrupted[headerSize:]))
+
+			assert.NotPanics(t, func() {
+				function, err := ReadBytecode(bytes.NewReader(corrupted))
+				if err != nil {
+					assert.Nil(t, function)
+				}
+			}, "Expecting byte %d xor %x to be rejected or loaded without panic.", offset, mask)
+		}
+	}
+}
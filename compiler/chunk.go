@@ -0,0 +1,82 @@
+package compiler
+
+import (
+	"math"
+	"sort"
+
+	"github.com/th-lange/glox/scanner"
+)
+
+// Chunk is a sequence of bytecode instructions together with the constants they refer to.
+// Source positions are stored run-length encoded: a new LineStart is only added when the token changes.
+type Chunk struct {
+	Code      []byte
+	Constants []interface{}
+	Lines     []LineStart
+}
+
+// LineStart marks the first byte of the code, which was compiled from the given source token.
+// Position and Length are the bytes of the token in the source, runtime errors are underlined with them.
+type LineStart struct {
+	Offset   int
+	Line     int
+	Column   int
+	Position int
+	Length   int
+}
+
+func (chunk *Chunk) Write(b byte, token scanner.Token) {
+	start := LineStart{Offset: len(chunk.Code), Line: token.Line, Column: token.Column, Position: token.Position, Length: token.Length}
+	if len(chunk.Lines) == 0 || !chunk.Lines[len(chunk.Lines)-1].sameToken(start) {
+		chunk.Lines = append(chunk.Lines, start)
+	}
+	chunk.Code = append(chunk.Code, b)
+}
+
+func (chunk *Chunk) WriteOp(op OpCode, token scanner.Token) {
+	chunk.Write(byte(op), token)
+}
+
+func (start LineStart) sameToken(other LineStart) bool {
+	return start.Line == other.Line && start.Column == other.Column && start.Position == other.Position && start.Length == other.Length
+}
+
+// AddConstant returns the index of the value in the constants pool.
+// Numbers and strings are only stored once per chunk.
+func (chunk *Chunk) AddConstant(value interface{}) int {
+	switch value.(type) {
+	case float64, string:
+		for index, constant := range chunk.Constants {
+			if sameConstant(constant, value) {
+				return index
+			}
+		}
+	}
+	chunk.Constants = append(chunk.Constants, value)
+	return len(chunk.Constants) - 1
+}
+
+// sameConstant compares numbers by their bits, -0 and 0 are equal, but print differently
+func sameConstant(constant, value interface{}) bool {
+	if number, ok := value.(float64); ok {
+		other, ok := constant.(float64)
+		return ok && math.Float64bits(number) == math.Float64bits(other)
+	}
+	return constant == value
+}
+
+// Line returns the source line of the instruction at the offset.
+func (chunk *Chunk) Line(offset int) int {
+	return chunk.Location(offset).Line
+}
+
+// Location returns the line table entry of the instruction at the offset, line and column are zero if it is unknown.
+func (chunk *Chunk) Location(offset int) LineStart {
+	index := sort.Search(len(chunk.Lines), func(i int) bool {
+		return chunk.Lines[i].Offset > offset
+	})
+	if index == 0 {
+		return LineStart{}
+	}
+	return chunk.Lines[index-1]
+}
@@ -0,0 +1,68 @@
+package compiler
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/scanner"
+)
+
+// lineToken returns a token, which only knows its line
+func lineToken(line int) scanner.Token {
+	return scanner.Token{Line: line}
+}
+
+func TestChunk_Write(t *testing.T) {
+	chunk := Chunk{}
+	chunk.WriteOp(OP_NIL, lineToken(1))
+	chunk.WriteOp(OP_PRINT, lineToken(1))
+	chunk.WriteOp(OP_TRUE, lineToken(3))
+	chunk.WriteOp(OP_RETURN, lineToken(4))
+	chunk.WriteOp(OP_NIL, lineToken(4))
+
+	assert.Equal(t, []byte{byte(OP_NIL), byte(OP_PRINT), byte(OP_TRUE), byte(OP_RETURN), byte(OP_NIL)}, chunk.Code)
+	assert.Equal(t, []LineStart{{Offset: 0, Line: 1}, {Offset: 2, Line: 3}, {Offset: 3, Line: 4}}, chunk.Lines, "Expecting one entry per line change.")
+}
+
+func TestChunk_Line(t *testing.T) {
+	chunk := Chunk{}
+	chunk.WriteOp(OP_NIL, lineToken(1))
+	chunk.WriteOp(OP_PRINT, lineToken(1))
+	chunk.WriteOp(OP_TRUE, lineToken(3))
+	chunk.WriteOp(OP_RETURN, lineToken(4))
+
+	expected := []int{1, 1, 3, 4}
+	for offset, line := range expected {
+		assert.Equal(t, line, chunk.Line(offset), "Expecting the line of the instruction at the offset.")
+	}
+	assert.Equal(t, 0, (&Chunk{}).Line(0), "Expecting line zero for an empty chunk.")
+}
+
+func TestChunk_Location(t *testing.T) {
+	chunk := Chunk{}
+	chunk.WriteOp(OP_GET_GLOBAL, scanner.Token{Line: 2, Column: 7, Position: 12, Length: 4})
+	chunk.Write(0, scanner.Token{Line: 2, Column: 7, Position: 12, Length: 4})
+	chunk.WriteOp(OP_NEGATE, scanner.Token{Line: 2, Column: 6, Position: 11, Length: 1})
+
+	assert.Len(t, chunk.Lines, 2, "Expecting one entry per token change, even on the same line.")
+	assert.Equal(t, LineStart{Offset: 0, Line: 2, Column: 7, Position: 12, Length: 4}, chunk.Location(1))
+	assert.Equal(t, LineStart{Offset: 2, Line: 2, Column: 6, Position: 11, Length: 1}, chunk.Location(2))
+	assert.Equal(t, LineStart{}, (&Chunk{}).Location(0), "Expecting an empty entry for an empty chunk.")
+}
+
+func TestChunk_AddConstant(t *testing.T) {
+	chunk := Chunk{}
+	assert.Equal(t, 0, chunk.AddConstant(1.5))
+	assert.Equal(t, 1, chunk.AddConstant("name"))
+	assert.Equal(t, 0, chunk.AddConstant(1.5), "Expecting numbers to be stored only once.")
+	assert.Equal(t, 1, chunk.AddConstant("name"), "Expecting strings to be stored only once.")
+	assert.Equal(t, 2, chunk.AddConstant(0.0))
+	assert.Equal(t, 3, chunk.AddConstant(math.Copysign(0, -1)), "Expecting -0 to be stored apart from 0.")
+
+	function := &Function{Name: "f"}
+	assert.Equal(t, 4, chunk.AddConstant(function))
+	assert.Equal(t, 5, chunk.AddConstant(&Function{Name: "f"}), "Expecting functions to be stored every time.")
+	assert.Len(t, chunk.Constants, 6)
+}
@@ -0,0 +1,29 @@
+package compiler
+
+import (
+	"strconv"
+
+	"github.com/th-lange/glox/diagnostic"
+	"github.com/th-lange/glox/scanner"
+)
+
+// Indicates that the program exceeds a limit of the bytecode, e.g. too many local variables in one function
+type CompileError struct {
+	Token   scanner.Token
+	Message string
+}
+
+func (ce CompileError) Error() string {
+	return "[Line " + strconv.Itoa(ce.Token.Line) + "] CompileError at '" + ce.Token.Lexeme + "' (Column " + strconv.Itoa(ce.Token.Column) + "): " + ce.Message
+}
+
+func (ce CompileError) Diagnostic() diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{
+		Code:    diagnostic.COMPILE_ERROR,
+		Message: ce.Message,
+		Line:    ce.Token.Line,
+		Offset:  ce.Token.Position,
+		Length:  ce.Token.Length,
+		Hint:    "The limit exists in the bytecode vm only, the tree walker can run the script.",
+	}
+}
@@ -0,0 +1,627 @@
+package compiler
+
+import (
+	"github.com/th-lange/glox/expression"
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+)
+
+const (
+	maxLocals    = 256
+	maxUpvalues  = 256
+	maxConstants = 1 << 16
+	maxJump      = 1<<16 - 1
+)
+
+type functionKind int
+
+const (
+	SCRIPT functionKind = iota
+	FUNCTION
+	METHOD
+	INITIALIZER
+)
+
+type local struct {
+	name       string
+	depth      int
+	isCaptured bool
+}
+
+type upvalue struct {
+	index   byte
+	isLocal bool
+}
+
+// functionScope holds the state of the function currently being compiled.
+// Nested function declarations push a new functionScope, which links to the enclosing one.
+type functionScope struct {
+	enclosing  *functionScope
+	function   *Function
+	kind       functionKind
+	locals     []local
+	upvalues   []upvalue
+	scopeDepth int
+}
+
+// Compiler lowers the parsed and resolved statements into bytecode.
+// Local variables live in stack slots, variables captured by closures are accessed through upvalues.
+// Everything declared outside a block or function is a global.
+type Compiler struct {
+	current *functionScope
+	token   scanner.Token
+}
+
+// Compile turns the program into a Function without name, ready to be run by the vm.
+// Semantic errors must have been reported by the resolver already, only bytecode limits are checked here.
+func Compile(statements []statement.Stmt) (function *Function, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			compileError, ok := r.(CompileError)
+			if !ok {
+				panic(r)
+			}
+			function, err = nil, compileError
+		}
+	}()
+
+	cmp := &Compiler{}
+	cmp.beginFunction(SCRIPT, "")
+	for _, stmt := range statements {
+		cmp.compileStmt(stmt)
+	}
+	function, _ = cmp.endFunction()
+	return function, nil
+}
+
+func (cmp *Compiler) compileStmt(stmt statement.Stmt) {
+	stmt.Accept(cmp)
+}
+
+func (cmp *Compiler) compileExpression(expr expression.Expression) {
+	expr.Accept(cmp)
+}
+
+// setToken remembers the token for the line table of the following instructions and for error messages
+func (cmp *Compiler) setToken(token scanner.Token) {
+	cmp.token = token
+}
+
+func (cmp *Compiler) error(message string) {
+	panic(CompileError{cmp.token, message})
+}
+
+func (cmp *Compiler) chunk() *Chunk {
+	return &cmp.current.function.Chunk
+}
+
+func (cmp *Compiler) beginFunction(kind functionKind, name string) {
+	scope := &functionScope{
+		enclosing: cmp.current,
+		function:  &Function{Name: name},
+		kind:      kind,
+		locals:    make([]local, 0, 8),
+	}
+	// slot zero holds the called closure, or the instance for methods
+	slotZero := ""
+	if kind == METHOD || kind == INITIALIZER {
+		slotZero = "this"
+	}
+	scope.locals = append(scope.locals, local{name: slotZero})
+	cmp.current = scope
+}
+
+func (cmp *Compiler) endFunction() (*Function, []upvalue) {
+	cmp.emitReturn()
+	scope := cmp.current
+	cmp.current = scope.enclosing
+	return scope.function, scope.upvalues
+}
+
+func (cmp *Compiler) beginScope() {
+	cmp.current.scopeDepth++
+}
+
+// endScope discards the locals of the scope. Captured locals are moved to the heap instead.
+func (cmp *Compiler) endScope() {
+	scope := cmp.current
+	scope.scopeDepth--
+	for len(scope.locals) > 0 && scope.locals[len(scope.locals)-1].depth > scope.scopeDepth {
+		if scope.locals[len(scope.locals)-1].isCaptured {
+			cmp.emitOp(OP_CLOSE_UPVALUE)
+		} else {
+			cmp.emitOp(OP_POP)
+		}
+		scope.locals = scope.locals[:len(scope.locals)-1]
+	}
+}
+
+func (cmp *Compiler) emitByte(b byte) {
+	cmp.chunk().Write(b, cmp.token)
+}
+
+func (cmp *Compiler) emitOp(op OpCode) {
+	cmp.chunk().WriteOp(op, cmp.token)
+}
+
+func (cmp *Compiler) emitIndex(index int) {
+	cmp.emitByte(byte(index >> 8))
+	cmp.emitByte(byte(index))
+}
+
+func (cmp *Compiler) emitReturn() {
+	if cmp.current.kind == INITIALIZER {
+		cmp.emitOp(OP_GET_LOCAL)
+		cmp.emitByte(0)
+	} else {
+		cmp.emitOp(OP_NIL)
+	}
+	cmp.emitOp(OP_RETURN)
+}
+
+func (cmp *Compiler) makeConstant(value interface{}) int {
+	index := cmp.chunk().AddConstant(value)
+	if index >= maxConstants {
+		cmp.error("Too many constants in one chunk.")
+	}
+	return index
+}
+
+func (cmp *Compiler) emitConstant(value interface{}) {
+	cmp.emitOp(OP_CONSTANT)
+	cmp.emitIndex(cmp.makeConstant(value))
+}
+
+func (cmp *Compiler) identifierConstant(name scanner.Token) int {
+	return cmp.makeConstant(name.Lexeme)
+}
+
+// emitJump writes the jump with a placeholder offset and returns the position of the offset for patchJump
+func (cmp *Compiler) emitJump(op OpCode) int {
+	cmp.emitOp(op)
+	cmp.emitByte(0xff)
+	cmp.emitByte(0xff)
+	return len(cmp.chunk().Code) - 2
+}
+
+func (cmp *Compiler) patchJump(offset int) {
+	jump := len(cmp.chunk().Code) - offset - 2
+	if jump > maxJump {
+		cmp.error("Too much code to jump over.")
+	}
+	cmp.chunk().Code[offset] = byte(jump >> 8)
+	cmp.chunk().Code[offset+1] = byte(jump)
+}
+
+func (cmp *Compiler) emitLoop(loopStart int) {
+	cmp.emitOp(OP_LOOP)
+	offset := len(cmp.chunk().Code) - loopStart + 2
+	if offset > maxJump {
+		cmp.error("Loop body too large.")
+	}
+	cmp.emitIndex(offset)
+}
+
+func (cmp *Compiler) addLocal(name string) {
+	if len(cmp.current.locals) == maxLocals {
+		cmp.error("Too many local variables in function.")
+	}
+	cmp.current.locals = append(cmp.current.locals, local{name: name, depth: cmp.current.scopeDepth})
+}
+
+// declareVariable reserves the stack slot of a local variable. The value has to be pushed right after.
+func (cmp *Compiler) declareVariable(name scanner.Token) {
+	if cmp.current.scopeDepth > 0 {
+		cmp.addLocal(name.Lexeme)
+	}
+}
+
+// defineVariable stores the value on top of the stack as global, locals are already in their slot
+func (cmp *Compiler) defineVariable(name scanner.Token) {
+	if cmp.current.scopeDepth > 0 {
+		return
+	}
+	cmp.emitOp(OP_DEFINE_GLOBAL)
+	cmp.emitIndex(cmp.identifierConstant(name))
+}
+
+func resolveLocal(scope *functionScope, name string) int {
+	for i := len(scope.locals) - 1; i >= 0; i-- {
+		if scope.locals[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// resolveUpvalue looks the variable up in the enclosing functions and threads it through all functions in between
+func (cmp *Compiler) resolveUpvalue(scope *functionScope, name string) int {
+	if scope.enclosing == nil {
+		return -1
+	}
+	if index := resolveLocal(scope.enclosing, name); index != -1 {
+		scope.enclosing.locals[index].isCaptured = true
+		return cmp.addUpvalue(scope, byte(index), true)
+	}
+	if index := cmp.resolveUpvalue(scope.enclosing, name); index != -1 {
+		return cmp.addUpvalue(scope, byte(index), false)
+	}
+	return -1
+}
+
+func (cmp *Compiler) addUpvalue(scope *functionScope, index byte, isLocal bool) int {
+	for i, existing := range scope.upvalues {
+		if existing.index == index && existing.isLocal == isLocal {
+			return i
+		}
+	}
+	if len(scope.upvalues) == maxUpvalues {
+		cmp.error("Too many closure variables in function.")
+	}
+	scope.upvalues = append(scope.upvalues, upvalue{index: index, isLocal: isLocal})
+	scope.function.UpvalueCount = len(scope.upvalues)
+	return len(scope.upvalues) - 1
+}
+
+// namedVariable emits the access to a local, upvalue or global. For assignments the value is expected on the stack.
+func (cmp *Compiler) namedVariable(name scanner.Token, assign bool) {
+	cmp.setToken(name)
+	if slot := resolveLocal(cmp.current, name.Lexeme); slot != -1 {
+		cmp.emitVariableOp(OP_GET_LOCAL, OP_SET_LOCAL, assign)
+		cmp.emitByte(byte(slot))
+	} else if index := cmp.resolveUpvalue(cmp.current, name.Lexeme); index != -1 {
+		cmp.emitVariableOp(OP_GET_UPVALUE, OP_SET_UPVALUE, assign)
+		cmp.emitByte(byte(index))
+	} else {
+		cmp.emitVariableOp(OP_GET_GLOBAL, OP_SET_GLOBAL, assign)
+		cmp.emitIndex(cmp.identifierConstant(name))
+	}
+}
+
+func (cmp *Compiler) emitVariableOp(get, set OpCode, assign bool) {
+	if assign {
+		cmp.emitOp(set)
+	} else {
+		cmp.emitOp(get)
+	}
+}
+
+// function compiles the declaration into its own Function and emits the closure creation in the enclosing one
+func (cmp *Compiler) function(stmt statement.Function, kind functionKind) {
+	cmp.setToken(stmt.Name)
+	cmp.beginFunction(kind, stmt.Name.Lexeme)
+	cmp.beginScope()
+	for _, param := range stmt.Params {
+		cmp.setToken(param)
+		cmp.current.function.Arity++
+		cmp.addLocal(param.Lexeme)
+	}
+	for _, bodyStmt := range stmt.Body {
+		cmp.compileStmt(bodyStmt)
+	}
+	function, upvalues := cmp.endFunction()
+
+	cmp.setToken(stmt.Name)
+	cmp.emitOp(OP_CLOSURE)
+	cmp.emitIndex(cmp.makeConstant(function))
+	for _, upvalue := range upvalues {
+		if upvalue.isLocal {
+			cmp.emitByte(1)
+		} else {
+			cmp.emitByte(0)
+		}
+		cmp.emitByte(upvalue.index)
+	}
+}
+
+func (cmp *Compiler) VisitExpressionStmt(stmt statement.Expression) interface{} {
+	cmp.compileExpression(stmt.Expr)
+	cmp.emitOp(OP_POP)
+	return nil
+}
+
+func (cmp *Compiler) VisitPrintStmt(stmt statement.Print) interface{} {
+	cmp.compileExpression(stmt.Expr)
+	cmp.emitOp(OP_PRINT)
+	return nil
+}
+
+func (cmp *Compiler) VisitVarStmt(stmt statement.Var) interface{} {
+	if stmt.Initializer != nil {
+		cmp.compileExpression(stmt.Initializer)
+	} else {
+		cmp.setToken(stmt.Name)
+		cmp.emitOp(OP_NIL)
+	}
+	cmp.setToken(stmt.Name)
+	cmp.declareVariable(stmt.Name)
+	cmp.defineVariable(stmt.Name)
+	return nil
+}
+
+func (cmp *Compiler) VisitBlockStmt(stmt statement.Block) interface{} {
+	cmp.beginScope()
+	for _, blockStmt := range stmt.Statements {
+		cmp.compileStmt(blockStmt)
+	}
+	cmp.endScope()
+	return nil
+}
+
+func (cmp *Compiler) VisitClassStmt(stmt statement.Class) interface{} {
+	cmp.setToken(stmt.Name)
+	cmp.declareVariable(stmt.Name)
+	cmp.emitOp(OP_CLASS)
+	cmp.emitIndex(cmp.identifierConstant(stmt.Name))
+	cmp.defineVariable(stmt.Name)
+
+	if stmt.Superclass != nil {
+		cmp.namedVariable(stmt.Superclass.Name, false)
+		// the superclass stays on the stack as local "super", the methods capture it as upvalue
+		cmp.beginScope()
+		cmp.addLocal("super")
+		cmp.namedVariable(stmt.Name, false)
+		cmp.setToken(stmt.Superclass.Name)
+		cmp.emitOp(OP_INHERIT)
+	}
+
+	cmp.namedVariable(stmt.Name, false)
+	for _, method := range stmt.Methods {
+		kind := METHOD
+		if method.Name.Lexeme == "init" {
+			kind = INITIALIZER
+		}
+		cmp.function(method, kind)
+		cmp.emitOp(OP_METHOD)
+		cmp.emitIndex(cmp.identifierConstant(method.Name))
+	}
+	cmp.emitOp(OP_POP)
+
+	if stmt.Superclass != nil {
+		cmp.endScope()
+	}
+	return nil
+}
+
+func (cmp *Compiler) VisitFunctionStmt(stmt statement.Function) interface{} {
+	// the slot is reserved before the body is compiled, so the function can refer to itself
+	cmp.setToken(stmt.Name)
+	cmp.declareVariable(stmt.Name)
+	cmp.function(stmt, FUNCTION)
+	cmp.defineVariable(stmt.Name)
+	return nil
+}
+
+func (cmp *Compiler) VisitReturnStmt(stmt statement.Return) interface{} {
+	cmp.setToken(stmt.Keyword)
+	if stmt.Value == nil {
+		cmp.emitReturn()
+		return nil
+	}
+	cmp.compileExpression(stmt.Value)
+	cmp.emitOp(OP_RETURN)
+	return nil
+}
+
+func (cmp *Compiler) VisitIfStmt(stmt statement.If) interface{} {
+	cmp.compileExpression(stmt.Condition)
+	thenJump := cmp.emitJump(OP_JUMP_IF_FALSE)
+	cmp.emitOp(OP_POP)
+	cmp.compileStmt(stmt.ThenBranch)
+
+	elseJump := cmp.emitJump(OP_JUMP)
+	cmp.patchJump(thenJump)
+	cmp.emitOp(OP_POP)
+	if stmt.ElseBranch != nil {
+		cmp.compileStmt(stmt.ElseBranch)
+	}
+	cmp.patchJump(elseJump)
+	return nil
+}
+
+func (cmp *Compiler) VisitWhileStmt(stmt statement.While) interface{} {
+	loopStart := len(cmp.chunk().Code)
+	cmp.compileExpression(stmt.Condition)
+	exitJump := cmp.emitJump(OP_JUMP_IF_FALSE)
+	cmp.emitOp(OP_POP)
+	cmp.compileStmt(stmt.Body)
+	cmp.emitLoop(loopStart)
+
+	cmp.patchJump(exitJump)
+	cmp.emitOp(OP_POP)
+	return nil
+}
+
+func (cmp *Compiler) VisitBinary(expression expression.Binary) interface{} {
+	cmp.compileExpression(expression.Left)
+	cmp.compileExpression(expression.Right)
+
+	cmp.setToken(expression.Operator)
+	switch expression.Operator.Type {
+	case scanner.BANG_EQUAL:
+		cmp.emitOp(OP_EQUAL)
+		cmp.emitOp(OP_NOT)
+	case scanner.EQUAL_EQUAL:
+		cmp.emitOp(OP_EQUAL)
+	case scanner.GREATER:
+		cmp.emitOp(OP_GREATER)
+	case scanner.GREATER_EQUAL:
+		cmp.emitOp(OP_GREATER_EQUAL)
+	case scanner.LESS:
+		cmp.emitOp(OP_LESS)
+	case scanner.LESS_EQUAL:
+		cmp.emitOp(OP_LESS_EQUAL)
+	case scanner.PLUS:
+		cmp.emitOp(OP_ADD)
+	case scanner.MINUS:
+		cmp.emitOp(OP_SUBTRACT)
+	case scanner.STAR:
+		cmp.emitOp(OP_MULTIPLY)
+	case scanner.SLASH:
+		cmp.emitOp(OP_DIVIDE)
+	}
+	return nil
+}
+
+func (cmp *Compiler) VisitGrouping(expression expression.Grouping) interface{} {
+	cmp.compileExpression(expression.Expr)
+	return nil
+}
+
+func (cmp *Compiler) VisitLiteral(expression expression.Literal) interface{} {
+	cmp.setToken(expression.Value)
+	switch expression.Value.Type {
+	case scanner.TRUE:
+		cmp.emitOp(OP_TRUE)
+	case scanner.FALSE:
+		cmp.emitOp(OP_FALSE)
+	case scanner.NIL:
+		cmp.emitOp(OP_NIL)
+	default:
+		cmp.emitConstant(expression.Value.Literal)
+	}
+	return nil
+}
+
+func (cmp *Compiler) VisitUnary(expression expression.Unary) interface{} {
+	cmp.compileExpression(expression.Right)
+
+	cmp.setToken(expression.Operator)
+	switch expression.Operator.Type {
+	case scanner.BANG:
+		cmp.emitOp(OP_NOT)
+	case scanner.MINUS:
+		cmp.emitOp(OP_NEGATE)
+	}
+	return nil
+}
+
+func (cmp *Compiler) VisitVariable(expression expression.Variable) interface{} {
+	cmp.namedVariable(expression.Name, false)
+	return nil
+}
+
+func (cmp *Compiler) VisitAssign(expression expression.Assign) interface{} {
+	cmp.compileExpression(expression.Value)
+	cmp.namedVariable(expression.Name, true)
+	return nil
+}
+
+// VisitLogical leaves the deciding operand on the stack and skips the right one, if possible
+func (cmp *Compiler) VisitLogical(expression expression.Logical) interface{} {
+	cmp.compileExpression(expression.Left)
+
+	cmp.setToken(expression.Operator)
+	if expression.Operator.Type == scanner.OR {
+		elseJump := cmp.emitJump(OP_JUMP_IF_FALSE)
+		endJump := cmp.emitJump(OP_JUMP)
+		cmp.patchJump(elseJump)
+		cmp.emitOp(OP_POP)
+		cmp.compileExpression(expression.Right)
+		cmp.patchJump(endJump)
+		return nil
+	}
+
+	endJump := cmp.emitJump(OP_JUMP_IF_FALSE)
+	cmp.emitOp(OP_POP)
+	cmp.compileExpression(expression.Right)
+	cmp.patchJump(endJump)
+	return nil
+}
+
+// VisitCall fuses method calls into a single invoke instruction, which skips creating the bound method.
+// The method name is recorded for the opcode and the name of an invoke, the parenthesis for the argument count,
+// so a missing method is reported at its name and wrong arguments at the call like for the tree-walker.
+func (cmp *Compiler) VisitCall(expr expression.Call) interface{} {
+	switch callee := expr.Callee.(type) {
+	case expression.Get:
+		cmp.compileExpression(callee.Object)
+		cmp.compileArguments(expr.Arguments)
+		cmp.setToken(callee.Name)
+		cmp.emitOp(OP_INVOKE)
+		cmp.emitIndex(cmp.identifierConstant(callee.Name))
+	case expression.Super:
+		cmp.namedVariable(thisToken(callee.Keyword), false)
+		cmp.compileArguments(expr.Arguments)
+		cmp.namedVariable(callee.Keyword, false)
+		cmp.setToken(callee.Method)
+		cmp.emitOp(OP_SUPER_INVOKE)
+		cmp.emitIndex(cmp.identifierConstant(callee.Method))
+	default:
+		cmp.compileExpression(expr.Callee)
+		cmp.compileArguments(expr.Arguments)
+		cmp.setToken(expr.Paren)
+		cmp.emitOp(OP_CALL)
+	}
+	cmp.setToken(expr.Paren)
+	cmp.emitByte(byte(len(expr.Arguments)))
+	return nil
+}
+
+func (cmp *Compiler) compileArguments(arguments []expression.Expression) {
+	for _, argument := range arguments {
+		cmp.compileExpression(argument)
+	}
+}
+
+func (cmp *Compiler) VisitGet(expression expression.Get) interface{} {
+	cmp.compileExpression(expression.Object)
+	cmp.setToken(expression.Name)
+	cmp.emitOp(OP_GET_PROPERTY)
+	cmp.emitIndex(cmp.identifierConstant(expression.Name))
+	return nil
+}
+
+func (cmp *Compiler) VisitSet(expression expression.Set) interface{} {
+	cmp.compileExpression(expression.Object)
+	cmp.compileExpression(expression.Value)
+	cmp.setToken(expression.Name)
+	cmp.emitOp(OP_SET_PROPERTY)
+	cmp.emitIndex(cmp.identifierConstant(expression.Name))
+	return nil
+}
+
+func (cmp *Compiler) VisitThis(expression expression.This) interface{} {
+	cmp.namedVariable(expression.Keyword, false)
+	return nil
+}
+
+func (cmp *Compiler) VisitSuper(expression expression.Super) interface{} {
+	cmp.namedVariable(thisToken(expression.Keyword), false)
+	cmp.namedVariable(expression.Keyword, false)
+	cmp.setToken(expression.Method)
+	cmp.emitOp(OP_GET_SUPER)
+	cmp.emitIndex(cmp.identifierConstant(expression.Method))
+	return nil
+}
+
+// VisitInterpolation concatenates the parts, the embedded values are converted to strings first
+func (cmp *Compiler) VisitInterpolation(interpolation expression.Interpolation) interface{} {
+	for i, part := range interpolation.Parts {
+		cmp.compileExpression(part)
+		if !isStringLiteral(part) {
+			cmp.emitOp(OP_STRINGIFY)
+		}
+		if i > 0 {
+			cmp.emitOp(OP_ADD)
+		}
+	}
+	return nil
+}
+
+func isStringLiteral(expr expression.Expression) bool {
+	literal, ok := expr.(expression.Literal)
+	if !ok {
+		return false
+	}
+	_, ok = literal.Value.Literal.(string)
+	return ok
+}
+
+// thisToken creates the token to look up the instance of a super expression
+func thisToken(keyword scanner.Token) scanner.Token {
+	token := keyword
+	token.Type = scanner.THIS
+	token.Lexeme = "this"
+	return token
+}
@@ -0,0 +1,111 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/parser"
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+)
+
+func parseProgram(t *testing.T, source string) []statement.Stmt {
+	scnr := scanner.Scanner{}
+	scnr.Scan(source)
+	assert.False(t, scnr.HadError, "Expecting the source to be scanned without errors: "+source)
+	statements := parser.NewParser(&scnr.Tokens).Parse()
+	assert.NotNil(t, statements, "Expecting the source to be parsed without errors: "+source)
+	return statements
+}
+
+func TestCompile_Expression(t *testing.T) {
+	function, err := Compile(parseProgram(t, "print 1 + 2 * 1;"))
+	assert.NoError(t, err)
+
+	expected := []byte{
+		byte(OP_CONSTANT), 0, 0,
+		byte(OP_CONSTANT), 0, 1,
+		byte(OP_CONSTANT), 0, 0,
+		byte(OP_MULTIPLY),
+		byte(OP_ADD),
+		byte(OP_PRINT),
+		byte(OP_NIL),
+		byte(OP_RETURN),
+	}
+	assert.Equal(t, expected, function.Chunk.Code)
+	assert.Equal(t, []interface{}{1.0, 2.0}, function.Chunk.Constants)
+	assert.Equal(t, "<script>", function.String())
+}
+
+func TestCompile_Interpolation(t *testing.T) {
+	function, err := Compile(parseProgram(t, `print "a ${1} b";`))
+	assert.NoError(t, err)
+
+	expected := []byte{
+		byte(OP_CONSTANT), 0, 0,
+		byte(OP_CONSTANT), 0, 1,
+		byte(OP_STRINGIFY),
+		byte(OP_ADD),
+		byte(OP_CONSTANT), 0, 2,
+		byte(OP_ADD),
+		byte(OP_PRINT),
+		byte(OP_NIL),
+		byte(OP_RETURN),
+	}
+	assert.Equal(t, expected, function.Chunk.Code, "Expecting only the embedded value to be converted to a string.")
+	assert.Equal(t, []interface{}{"a ", 1.0, " b"}, function.Chunk.Constants)
+}
+
+func TestCompile_LocalsUseStackSlots(t *testing.T) {
+	function, err := Compile(parseProgram(t, "var g = 1;\n{\n  var a = g;\n  a = 2;\n}"))
+	assert.NoError(t, err)
+
+	expected := []byte{
+		byte(OP_CONSTANT), 0, 0,
+		byte(OP_DEFINE_GLOBAL), 0, 1,
+		byte(OP_GET_GLOBAL), 0, 1,
+		byte(OP_CONSTANT), 0, 2,
+		byte(OP_SET_LOCAL), 1,
+		byte(OP_POP),
+		byte(OP_POP),
+		byte(OP_NIL),
+		byte(OP_RETURN),
+	}
+	assert.Equal(t, expected, function.Chunk.Code)
+	assert.Equal(t, 1, function.Chunk.Line(0))
+	assert.Equal(t, 3, function.Chunk.Line(6), "Expecting the global read to be on line 3.")
+	assert.Equal(t, 4, function.Chunk.Line(9), "Expecting the assignment to be on line 4.")
+}
+
+func TestCompile_Closure(t *testing.T) {
+	function, err := Compile(parseProgram(t, "fun outer(a) {\n  fun inner() { return a; }\n  return inner;\n}"))
+	assert.NoError(t, err)
+
+	outer := function.Chunk.Constants[0].(*Function)
+	assert.Equal(t, "<fn outer>", outer.String())
+	assert.Equal(t, 1, outer.Arity)
+	assert.Equal(t, 0, outer.UpvalueCount)
+
+	inner := outer.Chunk.Constants[0].(*Function)
+	assert.Equal(t, 1, inner.UpvalueCount, "Expecting the parameter to be captured.")
+	assert.Equal(t, []byte{byte(OP_CLOSURE), 0, 0, 1, 1}, outer.Chunk.Code[:5], "Expecting the upvalue to reference the local slot of the parameter.")
+	assert.Equal(t, []byte{byte(OP_GET_UPVALUE), 0, byte(OP_RETURN)}, inner.Chunk.Code[:3])
+}
+
+func TestCompile_TooManyLocals(t *testing.T) {
+	sb := strings.Builder{}
+	sb.WriteString("{\n")
+	for i := 0; i < maxLocals; i++ {
+		sb.WriteString("var a" + strings.Repeat("x", i) + ";\n")
+	}
+	sb.WriteString("}")
+
+	function, err := Compile(parseProgram(t, sb.String()))
+	assert.Nil(t, function)
+	if assert.IsType(t, CompileError{}, err) {
+		assert.Equal(t, "Too many local variables in function.", err.(CompileError).Message)
+		assert.Equal(t, maxLocals+1, err.(CompileError).Token.Line, "Expecting the error at the first variable without slot.")
+	}
+}
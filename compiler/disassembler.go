@@ -0,0 +1,105 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Disassemble prints all instructions of the function and of the functions declared within it.
+func Disassemble(out io.Writer, function *Function) {
+	fmt.Fprintf(out, "== %s ==\n", function)
+	for offset := 0; offset < len(function.Chunk.Code); {
+		offset = DisassembleInstruction(out, &function.Chunk, offset)
+	}
+
+	for _, constant := range function.Chunk.Constants {
+		if nested, ok := constant.(*Function); ok {
+			fmt.Fprintln(out)
+			Disassemble(out, nested)
+		}
+	}
+}
+
+// DisassembleInstruction prints the instruction at the offset as
+// offset, source line (or | for the line of the previous instruction), opcode and operands.
+// It returns the offset of the next instruction.
+func DisassembleInstruction(out io.Writer, chunk *Chunk, offset int) int {
+	fmt.Fprintf(out, "%04d ", offset)
+	if offset > 0 && chunk.Line(offset) == chunk.Line(offset-1) {
+		fmt.Fprint(out, "   | ")
+	} else {
+		fmt.Fprintf(out, "%4d ", chunk.Line(offset))
+	}
+
+	op := OpCode(chunk.Code[offset])
+	switch op {
+	case OP_CONSTANT, OP_GET_GLOBAL, OP_DEFINE_GLOBAL, OP_SET_GLOBAL, OP_GET_PROPERTY, OP_SET_PROPERTY,
+		OP_GET_SUPER, OP_CLASS, OP_METHOD:
+		return constantInstruction(out, op, chunk, offset)
+	case OP_GET_LOCAL, OP_SET_LOCAL, OP_GET_UPVALUE, OP_SET_UPVALUE, OP_CALL:
+		return byteInstruction(out, op, chunk, offset)
+	case OP_JUMP, OP_JUMP_IF_FALSE:
+		return jumpInstruction(out, op, 1, chunk, offset)
+	case OP_LOOP:
+		return jumpInstruction(out, op, -1, chunk, offset)
+	case OP_INVOKE, OP_SUPER_INVOKE:
+		return invokeInstruction(out, op, chunk, offset)
+	case OP_CLOSURE:
+		return closureInstruction(out, chunk, offset)
+	}
+	fmt.Fprintln(out, op)
+	return offset + 1
+}
+
+func readIndex(chunk *Chunk, offset int) int {
+	return int(chunk.Code[offset])<<8 | int(chunk.Code[offset+1])
+}
+
+func constantInstruction(out io.Writer, op OpCode, chunk *Chunk, offset int) int {
+	index := readIndex(chunk, offset+1)
+	fmt.Fprintf(out, "%-16s %4d '%s'\n", op, index, formatConstant(chunk.Constants[index]))
+	return offset + 3
+}
+
+func byteInstruction(out io.Writer, op OpCode, chunk *Chunk, offset int) int {
+	fmt.Fprintf(out, "%-16s %4d\n", op, chunk.Code[offset+1])
+	return offset + 2
+}
+
+func jumpInstruction(out io.Writer, op OpCode, sign int, chunk *Chunk, offset int) int {
+	jump := readIndex(chunk, offset+1)
+	fmt.Fprintf(out, "%-16s %4d -> %d\n", op, offset, offset+3+sign*jump)
+	return offset + 3
+}
+
+func invokeInstruction(out io.Writer, op OpCode, chunk *Chunk, offset int) int {
+	index := readIndex(chunk, offset+1)
+	argCount := chunk.Code[offset+3]
+	fmt.Fprintf(out, "%-16s (%d args) %4d '%s'\n", op, argCount, index, formatConstant(chunk.Constants[index]))
+	return offset + 4
+}
+
+func closureInstruction(out io.Writer, chunk *Chunk, offset int) int {
+	index := readIndex(chunk, offset+1)
+	function := chunk.Constants[index].(*Function)
+	fmt.Fprintf(out, "%-16s %4d %s\n", OP_CLOSURE, index, function)
+
+	offset += 3
+	for i := 0; i < function.UpvalueCount; i++ {
+		kind := "upvalue"
+		if chunk.Code[offset] == 1 {
+			kind = "local"
+		}
+		fmt.Fprintf(out, "%04d    |                     %s %d\n", offset, kind, chunk.Code[offset+1])
+		offset += 2
+	}
+	return offset
+}
+
+func formatConstant(constant interface{}) string {
+	if number, ok := constant.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(constant)
+}
@@ -0,0 +1,68 @@
+package compiler
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisassembleInstruction(t *testing.T) {
+	chunk := Chunk{}
+	chunk.WriteOp(OP_CONSTANT, lineToken(1))
+	chunk.Write(0, lineToken(1))
+	chunk.Write(byte(chunk.AddConstant(1.5)), lineToken(1))
+	chunk.WriteOp(OP_GET_LOCAL, lineToken(1))
+	chunk.Write(3, lineToken(1))
+	chunk.WriteOp(OP_JUMP_IF_FALSE, lineToken(2))
+	chunk.Write(0, lineToken(2))
+	chunk.Write(4, lineToken(2))
+	chunk.WriteOp(OP_INVOKE, lineToken(2))
+	chunk.Write(0, lineToken(2))
+	chunk.Write(byte(chunk.AddConstant("method")), lineToken(2))
+	chunk.Write(2, lineToken(2))
+	chunk.WriteOp(OP_RETURN, lineToken(3))
+
+	expected := []string{
+		"0000    1 OP_CONSTANT         0 '1.5'\n",
+		"0003    | OP_GET_LOCAL        3\n",
+		"0005    2 OP_JUMP_IF_FALSE    5 -> 12\n",
+		"0008    | OP_INVOKE        (2 args)    1 'method'\n",
+		"0012    3 OP_RETURN\n",
+	}
+	offset := 0
+	for _, line := range expected {
+		out := bytes.Buffer{}
+		offset = DisassembleInstruction(&out, &chunk, offset)
+		assert.Equal(t, line, out.String())
+	}
+	assert.Equal(t, len(chunk.Code), offset, "Expecting all instructions to be disassembled.")
+}
+
+func TestDisassemble(t *testing.T) {
+	function, err := Compile(parseProgram(t, "fun outer() {\n  var a = 1;\n  fun inner() { return a; }\n}"))
+	assert.NoError(t, err)
+
+	out := bytes.Buffer{}
+	Disassemble(&out, function)
+	expected := `== <script> ==
+0000    1 OP_CLOSURE          0 <fn outer>
+0003    | OP_DEFINE_GLOBAL    1 'outer'
+0006    | OP_NIL
+0007    | OP_RETURN
+
+== <fn outer> ==
+0000    2 OP_CONSTANT         0 '1'
+0003    3 OP_CLOSURE          1 <fn inner>
+0006    |                     local 1
+0008    | OP_NIL
+0009    | OP_RETURN
+
+== <fn inner> ==
+0000    3 OP_GET_UPVALUE      0
+0002    | OP_RETURN
+0003    | OP_NIL
+0004    | OP_RETURN
+`
+	assert.Equal(t, expected, out.String(), "Expecting nested functions to follow the script.")
+}
@@ -0,0 +1,17 @@
+package compiler
+
+// Function is the compiled prototype of a lox function.
+// The top level code of a program is compiled into a Function without name.
+type Function struct {
+	Name         string
+	Arity        int
+	UpvalueCount int
+	Chunk        Chunk
+}
+
+func (function *Function) String() string {
+	if function.Name == "" {
+		return "<script>"
+	}
+	return "<fn " + function.Name + ">"
+}
@@ -0,0 +1,143 @@
+package compiler
+
+// OpCode is the first byte of every instruction in a Chunk.
+// The operands, if any, follow directly after the opcode.
+type OpCode byte
+
+const (
+	// Constants and literals.
+	OP_CONSTANT OpCode = iota // 2 byte constant index
+	OP_NIL
+	OP_TRUE
+	OP_FALSE
+	OP_POP
+
+	// Variables.
+	OP_GET_LOCAL     // 1 byte stack slot
+	OP_SET_LOCAL     // 1 byte stack slot
+	OP_GET_GLOBAL    // 2 byte constant index of the name
+	OP_DEFINE_GLOBAL // 2 byte constant index of the name
+	OP_SET_GLOBAL    // 2 byte constant index of the name
+	OP_GET_UPVALUE   // 1 byte upvalue index
+	OP_SET_UPVALUE   // 1 byte upvalue index
+	OP_GET_PROPERTY  // 2 byte constant index of the name
+	OP_SET_PROPERTY  // 2 byte constant index of the name
+	OP_GET_SUPER     // 2 byte constant index of the name
+
+	// Operators.
+	OP_EQUAL
+	OP_GREATER
+	OP_GREATER_EQUAL
+	OP_LESS
+	OP_LESS_EQUAL
+	OP_ADD
+	OP_SUBTRACT
+	OP_MULTIPLY
+	OP_DIVIDE
+	OP_NOT
+	OP_NEGATE
+	OP_STRINGIFY // converts the value to a string, like print does
+
+	// Statements and control flow.
+	OP_PRINT
+	OP_JUMP          // 2 byte forward offset
+	OP_JUMP_IF_FALSE // 2 byte forward offset
+	OP_LOOP          // 2 byte backward offset
+	OP_CALL          // 1 byte argument count
+	OP_INVOKE        // 2 byte constant index of the name, 1 byte argument count
+	OP_SUPER_INVOKE  // 2 byte constant index of the name, 1 byte argument count
+	OP_CLOSURE       // 2 byte constant index of the function, 2 bytes (isLocal, index) per upvalue
+	OP_CLOSE_UPVALUE
+	OP_RETURN
+
+	// Classes.
+	OP_CLASS // 2 byte constant index of the name
+	OP_INHERIT
+	OP_METHOD // 2 byte constant index of the name
+)
+
+func (op OpCode) String() string {
+	switch op {
+	case OP_CONSTANT:
+		return "OP_CONSTANT"
+	case OP_NIL:
+		return "OP_NIL"
+	case OP_TRUE:
+		return "OP_TRUE"
+	case OP_FALSE:
+		return "OP_FALSE"
+	case OP_POP:
+		return "OP_POP"
+	case OP_GET_LOCAL:
+		return "OP_GET_LOCAL"
+	case OP_SET_LOCAL:
+		return "OP_SET_LOCAL"
+	case OP_GET_GLOBAL:
+		return "OP_GET_GLOBAL"
+	case OP_DEFINE_GLOBAL:
+		return "OP_DEFINE_GLOBAL"
+	case OP_SET_GLOBAL:
+		return "OP_SET_GLOBAL"
+	case OP_GET_UPVALUE:
+		return "OP_GET_UPVALUE"
+	case OP_SET_UPVALUE:
+		return "OP_SET_UPVALUE"
+	case OP_GET_PROPERTY:
+		return "OP_GET_PROPERTY"
+	case OP_SET_PROPERTY:
+		return "OP_SET_PROPERTY"
+	case OP_GET_SUPER:
+		return "OP_GET_SUPER"
+	case OP_EQUAL:
+		return "OP_EQUAL"
+	case OP_GREATER:
+		return "OP_GREATER"
+	case OP_GREATER_EQUAL:
+		return "OP_GREATER_EQUAL"
+	case OP_LESS:
+		return "OP_LESS"
+	case OP_LESS_EQUAL:
+		return "OP_LESS_EQUAL"
+	case OP_ADD:
+		return "OP_ADD"
+	case OP_SUBTRACT:
+		return "OP_SUBTRACT"
+	case OP_MULTIPLY:
+		return "OP_MULTIPLY"
+	case OP_DIVIDE:
+		return "OP_DIVIDE"
+	case OP_NOT:
+		return "OP_NOT"
+	case OP_NEGATE:
+		return "OP_NEGATE"
+	case OP_STRINGIFY:
+		return "OP_STRINGIFY"
+	case OP_PRINT:
+		return "OP_PRINT"
+	case OP_JUMP:
+		return "OP_JUMP"
+	case OP_JUMP_IF_FALSE:
+		return "OP_JUMP_IF_FALSE"
+	case OP_LOOP:
+		return "OP_LOOP"
+	case OP_CALL:
+		return "OP_CALL"
+	case OP_INVOKE:
+		return "OP_INVOKE"
+	case OP_SUPER_INVOKE:
+		return "OP_SUPER_INVOKE"
+	case OP_CLOSURE:
+		return "OP_CLOSURE"
+	case OP_CLOSE_UPVALUE:
+		return "OP_CLOSE_UPVALUE"
+	case OP_RETURN:
+		return "OP_RETURN"
+	case OP_CLASS:
+		return "OP_CLASS"
+	case OP_INHERIT:
+		return "OP_INHERIT"
+	case OP_METHOD:
+		return "OP_METHOD"
+	}
+	return "OP_UNKNOWN"
+}
@@ -0,0 +1,249 @@
+package compiler
+
+import (
+	"strconv"
+)
+
+// stackEffect describes how an instruction changes the stack of its frame:
+// it needs the top "needs" values and leaves the stack "delta" values higher.
+type stackEffect struct {
+	needs int
+	delta int
+}
+
+var stackEffects = map[OpCode]stackEffect{
+	OP_CONSTANT:      {0, 1},
+	OP_NIL:           {0, 1},
+	OP_TRUE:          {0, 1},
+	OP_FALSE:         {0, 1},
+	OP_POP:           {1, -1},
+	OP_GET_LOCAL:     {0, 1},
+	OP_SET_LOCAL:     {1, 0},
+	OP_GET_GLOBAL:    {0, 1},
+	OP_DEFINE_GLOBAL: {1, -1},
+	OP_SET_GLOBAL:    {1, 0},
+	OP_GET_UPVALUE:   {0, 1},
+	OP_SET_UPVALUE:   {1, 0},
+	OP_GET_PROPERTY:  {1, 0},
+	OP_SET_PROPERTY:  {2, -1},
+	OP_GET_SUPER:     {2, -1},
+	OP_EQUAL:         {2, -1},
+	OP_GREATER:       {2, -1},
+	OP_GREATER_EQUAL: {2, -1},
+	OP_LESS:          {2, -1},
+	OP_LESS_EQUAL:    {2, -1},
+	OP_ADD:           {2, -1},
+	OP_SUBTRACT:      {2, -1},
+	OP_MULTIPLY:      {2, -1},
+	OP_DIVIDE:        {2, -1},
+	OP_NOT:           {1, 0},
+	OP_NEGATE:        {1, 0},
+	OP_STRINGIFY:     {1, 0},
+	OP_PRINT:         {1, -1},
+	OP_JUMP:          {0, 0},
+	OP_JUMP_IF_FALSE: {1, 0},
+	OP_LOOP:          {0, 0},
+	OP_CALL:          {1, 0}, // depends on the argument count
+	OP_INVOKE:        {1, 0},
+	OP_SUPER_INVOKE:  {2, -1},
+	OP_CLOSURE:       {0, 1},
+	OP_CLOSE_UPVALUE: {1, -1},
+	OP_RETURN:        {1, -1},
+	OP_CLASS:         {0, 1},
+	OP_INHERIT:       {2, -1},
+	OP_METHOD:        {2, -1},
+}
+
+// verifier checks a loaded function, so the vm can run it without failing on its structure. The code is walked
+// along every path: opcodes have to be known, operands inside of the code, constants of the expected kind,
+// jumps have to end on an instruction and the stack may never drop below the callee and the parameters.
+// Broken functions are reported as FormatError panic, like the decoder does.
+type verifier struct {
+	function *Function
+	depths   map[int]int
+	pending  []int
+}
+
+func verifyScript(function *Function) {
+	if function.Arity != 0 || function.UpvalueCount != 0 {
+		panic(FormatError{"The script can't have parameters or upvalues."})
+	}
+	verifyFunction(function)
+}
+
+func verifyFunction(function *Function) {
+	vrfr := &verifier{function: function, depths: make(map[int]int)}
+	vrfr.verifyLines()
+	// slot 0 holds the callee, the parameters follow
+	vrfr.branch(0, function.Arity+1)
+	for len(vrfr.pending) > 0 {
+		offset := vrfr.pending[len(vrfr.pending)-1]
+		vrfr.pending = vrfr.pending[:len(vrfr.pending)-1]
+		vrfr.verifyInstruction(offset)
+	}
+
+	for _, constant := range function.Chunk.Constants {
+		if nested, ok := constant.(*Function); ok {
+			verifyFunction(nested)
+		}
+	}
+}
+
+func (vrfr *verifier) fail(offset int, message string) {
+	panic(FormatError{message + " (" + vrfr.function.String() + ", offset " + strconv.Itoa(offset) + ")"})
+}
+
+func (vrfr *verifier) verifyLines() {
+	lines := vrfr.function.Chunk.Lines
+	if len(vrfr.function.Chunk.Code) == 0 {
+		vrfr.fail(0, "The code is empty.")
+	}
+	if len(lines) == 0 || lines[0].Offset != 0 {
+		vrfr.fail(0, "The line table doesn't start with the code.")
+	}
+	for i, start := range lines {
+		if start.Offset >= len(vrfr.function.Chunk.Code) || i > 0 && start.Offset <= lines[i-1].Offset {
+			vrfr.fail(start.Offset, "The line table doesn't match the code.")
+		}
+	}
+}
+
+// branch continues the walk at offset with the stack depth, every offset is walked once
+func (vrfr *verifier) branch(offset int, depth int) {
+	if offset < 0 || offset >= len(vrfr.function.Chunk.Code) {
+		vrfr.fail(offset, "Jump out of the code.")
+	}
+	if known, ok := vrfr.depths[offset]; ok {
+		if known != depth {
+			vrfr.fail(offset, "Inconsistent stack depth.")
+		}
+		return
+	}
+	vrfr.depths[offset] = depth
+	vrfr.pending = append(vrfr.pending, offset)
+}
+
+func (vrfr *verifier) byteOperand(offset int, index int) int {
+	code := vrfr.function.Chunk.Code
+	if offset+index >= len(code) {
+		vrfr.fail(offset, "The instruction is truncated.")
+	}
+	return int(code[offset+index])
+}
+
+func (vrfr *verifier) shortOperand(offset int, index int) int {
+	return vrfr.byteOperand(offset, index)<<8 | vrfr.byteOperand(offset, index+1)
+}
+
+// constant returns the constant of the 2 byte operand following the opcode
+func (vrfr *verifier) constant(offset int) interface{} {
+	index := vrfr.shortOperand(offset, 1)
+	if index >= len(vrfr.function.Chunk.Constants) {
+		vrfr.fail(offset, "Constant index "+strconv.Itoa(index)+" is out of range.")
+	}
+	return vrfr.function.Chunk.Constants[index]
+}
+
+func (vrfr *verifier) name(offset int) {
+	if _, ok := vrfr.constant(offset).(string); !ok {
+		vrfr.fail(offset, "The constant has to be a name.")
+	}
+}
+
+func (vrfr *verifier) upvalue(offset int, index int) {
+	if index >= vrfr.function.UpvalueCount {
+		vrfr.fail(offset, "Upvalue "+strconv.Itoa(index)+" is out of range.")
+	}
+}
+
+func (vrfr *verifier) local(offset int, slot int, depth int) {
+	if slot >= depth {
+		vrfr.fail(offset, "Local slot "+strconv.Itoa(slot)+" is out of range.")
+	}
+}
+
+func (vrfr *verifier) verifyInstruction(offset int) {
+	depth := vrfr.depths[offset]
+	op := OpCode(vrfr.function.Chunk.Code[offset])
+	effect, ok := stackEffects[op]
+	if !ok {
+		vrfr.fail(offset, "Unknown opcode "+strconv.Itoa(int(op))+".")
+	}
+
+	size := 1
+	switch op {
+	case OP_CONSTANT:
+		switch vrfr.constant(offset).(type) {
+		case float64, string:
+		default:
+			vrfr.fail(offset, "The constant has to be a number or a string.")
+		}
+		size = 3
+	case OP_GET_GLOBAL, OP_DEFINE_GLOBAL, OP_SET_GLOBAL, OP_GET_PROPERTY, OP_SET_PROPERTY, OP_GET_SUPER, OP_CLASS, OP_METHOD:
+		vrfr.name(offset)
+		size = 3
+	case OP_GET_LOCAL, OP_SET_LOCAL:
+		vrfr.local(offset, vrfr.byteOperand(offset, 1), depth)
+		size = 2
+	case OP_GET_UPVALUE, OP_SET_UPVALUE:
+		vrfr.upvalue(offset, vrfr.byteOperand(offset, 1))
+		size = 2
+	case OP_CALL:
+		argCount := vrfr.byteOperand(offset, 1)
+		effect = stackEffect{needs: argCount + 1, delta: -argCount}
+		size = 2
+	case OP_INVOKE, OP_SUPER_INVOKE:
+		vrfr.name(offset)
+		argCount := vrfr.byteOperand(offset, 3)
+		effect = stackEffect{needs: argCount + 1, delta: -argCount}
+		if op == OP_SUPER_INVOKE {
+			// the superclass is popped before the call
+			effect = stackEffect{needs: argCount + 2, delta: -argCount - 1}
+		}
+		size = 4
+	case OP_JUMP, OP_JUMP_IF_FALSE, OP_LOOP:
+		size = 3
+	case OP_CLOSURE:
+		function, ok := vrfr.constant(offset).(*Function)
+		if !ok {
+			vrfr.fail(offset, "The constant has to be a function.")
+		}
+		size = 3
+		for i := 0; i < function.UpvalueCount; i++ {
+			isLocal, index := vrfr.byteOperand(offset, size), vrfr.byteOperand(offset, size+1)
+			switch isLocal {
+			case 0:
+				vrfr.upvalue(offset, index)
+			case 1:
+				// a local function may capture itself, it is stored in the slot the closure is pushed to
+				vrfr.local(offset, index, depth+1)
+			default:
+				vrfr.fail(offset, "Invalid upvalue flag "+strconv.Itoa(isLocal)+".")
+			}
+			size += 2
+		}
+	}
+
+	// the callee in slot 0 is never popped
+	if depth-effect.needs < 1 {
+		vrfr.fail(offset, "Stack underflow.")
+	}
+	depth += effect.delta
+
+	switch op {
+	case OP_RETURN:
+		return
+	case OP_JUMP:
+		vrfr.branch(offset+size+vrfr.shortOperand(offset, 1), depth)
+		return
+	case OP_LOOP:
+		vrfr.branch(offset+size-vrfr.shortOperand(offset, 1), depth)
+		return
+	case OP_JUMP_IF_FALSE:
+		vrfr.branch(offset+size+vrfr.shortOperand(offset, 1), depth)
+	}
+	if offset+size >= len(vrfr.function.Chunk.Code) {
+		vrfr.fail(offset, "The code ends without return.")
+	}
+	vrfr.branch(offset+size, depth)
+}
@@ -0,0 +1,163 @@
+package diagnostic
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error codes of the phases, every diagnostic carries the code of the phase which reported it.
+const (
+	SCANNER_ERROR  = "E0001"
+	PARSING_ERROR  = "E0002"
+	RESOLVER_ERROR = "E0003"
+	COMPILE_ERROR  = "E0004"
+	RUNTIME_ERROR  = "E0005"
+)
+
+// Diagnostic describes an error at a location in the source.
+// Offset is the byte offset of the erroneous code in the source, -1 if only the line is known.
+// Length is the number of bytes underlined, at least the first character is marked.
+type Diagnostic struct {
+	Code    string
+	Message string
+	Line    int
+	Offset  int
+	Length  int
+	Hint    string
+}
+
+// Reportable is implemented by the errors of all phases, which know where in the source they occurred.
+type Reportable interface {
+	error
+	Diagnostic() Diagnostic
+}
+
+const (
+	colorReset = "\x1b[0m"
+	colorError = "\x1b[1;31m"
+	colorFrame = "\x1b[1;34m"
+	colorBold  = "\x1b[1m"
+)
+
+// Renderer prints diagnostics for one source like rustc does:
+//
+//	error[E0002]: Expect expression.
+//	 --> script.lox:3:7
+//	  |
+//	3 | print ;
+//	  |       ^
+//	  = hint: ...
+//
+// With Color the output is highlighted with ANSI escape codes.
+type Renderer struct {
+	File   string
+	Source string
+	Color  bool
+}
+
+func NewRenderer(file, source string, color bool) *Renderer {
+	return &Renderer{File: file, Source: source, Color: color}
+}
+
+// IsTerminal reports, whether the file is a character device, so colored output can be displayed
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Render writes the diagnostic of the error. Errors without location are written as they are.
+func (rndr *Renderer) Render(out io.Writer, err error) {
+	reportable, ok := err.(Reportable)
+	if !ok {
+		io.WriteString(out, rndr.paint(colorError, "error")+rndr.paint(colorBold, ": "+err.Error())+"\n")
+		return
+	}
+	io.WriteString(out, rndr.Format(reportable.Diagnostic()))
+}
+
+// Format renders the diagnostic including the excerpt of the source
+func (rndr *Renderer) Format(diag Diagnostic) string {
+	sb := strings.Builder{}
+	sb.WriteString(rndr.paint(colorError, "error["+diag.Code+"]") + rndr.paint(colorBold, ": "+diag.Message) + "\n")
+
+	line, column, excerpt, found := rndr.locate(diag)
+	gutter := strings.Repeat(" ", len(strconv.Itoa(line)))
+	location := rndr.File + ":" + strconv.Itoa(line)
+	if column > 0 {
+		location += ":" + strconv.Itoa(column)
+	}
+	sb.WriteString(gutter + rndr.paint(colorFrame, "--> ") + location + "\n")
+
+	if found {
+		sb.WriteString(gutter + rndr.paint(colorFrame, " |") + "\n")
+		sb.WriteString(rndr.paint(colorFrame, strconv.Itoa(line)+" |") + " " + excerpt + "\n")
+		if column > 0 {
+			sb.WriteString(gutter + rndr.paint(colorFrame, " |") + " " + rndr.underline(excerpt, column, diag) + "\n")
+		}
+	}
+	if diag.Hint != "" {
+		sb.WriteString(gutter + rndr.paint(colorFrame, " =") + rndr.paint(colorBold, " hint: ") + diag.Hint + "\n")
+	}
+	return sb.String()
+}
+
+// locate finds the line of the diagnostic in the source. If the offset is known, the line and
+// the 1-based column are derived from it, otherwise only the reported line is used.
+func (rndr *Renderer) locate(diag Diagnostic) (line, column int, excerpt string, found bool) {
+	if diag.Offset < 0 || diag.Offset > len(rndr.Source) {
+		lines := strings.Split(rndr.Source, "\n")
+		if diag.Line < 1 || diag.Line > len(lines) {
+			return diag.Line, 0, "", false
+		}
+		return diag.Line, 0, strings.TrimRight(lines[diag.Line-1], "\r"), true
+	}
+
+	start := strings.LastIndexByte(rndr.Source[:diag.Offset], '\n') + 1
+	end := strings.IndexByte(rndr.Source[diag.Offset:], '\n')
+	if end < 0 {
+		end = len(rndr.Source)
+	} else {
+		end += diag.Offset
+	}
+	line = strings.Count(rndr.Source[:start], "\n") + 1
+	column = utf8.RuneCountInString(rndr.Source[start:diag.Offset]) + 1
+	return line, column, strings.TrimRight(rndr.Source[start:end], "\r"), true
+}
+
+// underline marks the erroneous code with ^~~~. Tabs before it are kept, so the marks line up with the excerpt.
+func (rndr *Renderer) underline(excerpt string, column int, diag Diagnostic) string {
+	sb := strings.Builder{}
+	runes := []rune(excerpt)
+	for i := 0; i < column-1 && i < len(runes); i++ {
+		if runes[i] == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+
+	// the underline ends at the end of the line, multi line tokens are only marked on their first line
+	width := 1
+	if column-1 < len(runes) && diag.Length > 1 {
+		rest := string(runes[column-1:])
+		length := diag.Length
+		if length > len(rest) {
+			length = len(rest)
+		}
+		width = utf8.RuneCountInString(rest[:length])
+	}
+	if width < 1 {
+		width = 1
+	}
+	return sb.String() + rndr.paint(colorError, "^"+strings.Repeat("~", width-1))
+}
+
+func (rndr *Renderer) paint(color, text string) string {
+	if !rndr.Color {
+		return text
+	}
+	return color + text + colorReset
+}
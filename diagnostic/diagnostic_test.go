@@ -0,0 +1,84 @@
+package diagnostic
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderer_Format(t *testing.T) {
+	rndr := NewRenderer("script.lox", "var a = 1;\nprint a + nil;\n", false)
+
+	result := rndr.Format(Diagnostic{Code: RUNTIME_ERROR, Message: "Operands must be numbers.", Line: 2, Offset: 21, Length: 3, Hint: "Check the types."})
+	assert.Equal(t, `error[E0005]: Operands must be numbers.
+ --> script.lox:2:11
+  |
+2 | print a + nil;
+  |           ^~~
+  = hint: Check the types.
+`, result)
+}
+
+func TestRenderer_Format_Column(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		offset   int
+		length   int
+		expected string
+	}{
+		{"first character", "abc", 0, 1, "1 | abc\n  | ^\n"},
+		{"end of source", "abc", 3, 0, "1 | abc\n  |    ^\n"},
+		{"tabs are kept", "\tabc", 1, 3, "1 | \tabc\n  | \t^~~\n"},
+		{"columns count runes", "\"äö\" x", 7, 1, "1 | \"äö\" x\n  |      ^\n"},
+		{"underline ends with the line", "abc\ndef", 1, 10, "1 | abc\n  |  ^~\n"},
+	}
+
+	for _, tt := range tests {
+		rndr := NewRenderer("f", tt.source, false)
+		result := rndr.Format(Diagnostic{Code: SCANNER_ERROR, Message: "m", Line: 1, Offset: tt.offset, Length: tt.length})
+		assert.Contains(t, result, tt.expected, tt.name)
+	}
+}
+
+func TestRenderer_Format_WithoutOffset(t *testing.T) {
+	rndr := NewRenderer("script.lox", "print 1;\nprint -nil;", false)
+
+	result := rndr.Format(Diagnostic{Code: RUNTIME_ERROR, Message: "Operand must be a number.", Line: 2, Offset: -1})
+	assert.Equal(t, "error[E0005]: Operand must be a number.\n --> script.lox:2\n  |\n2 | print -nil;\n", result, "Expecting the line without underline.")
+
+	result = rndr.Format(Diagnostic{Code: RUNTIME_ERROR, Message: "Operand must be a number.", Line: 12, Offset: -1})
+	assert.Equal(t, "error[E0005]: Operand must be a number.\n  --> script.lox:12\n", result, "Expecting no excerpt, if the line is not part of the source.")
+}
+
+func TestRenderer_Format_Color(t *testing.T) {
+	rndr := NewRenderer("f", "abc", true)
+
+	result := rndr.Format(Diagnostic{Code: PARSING_ERROR, Message: "m", Line: 1, Offset: 0, Length: 2})
+	assert.Contains(t, result, colorError+"error[E0002]"+colorReset)
+	assert.Contains(t, result, colorError+"^~"+colorReset)
+}
+
+type locatedError struct{}
+
+func (locatedError) Error() string {
+	return "located"
+}
+
+func (locatedError) Diagnostic() Diagnostic {
+	return Diagnostic{Code: RESOLVER_ERROR, Message: "located", Line: 1, Offset: 0, Length: 1}
+}
+
+func TestRenderer_Render(t *testing.T) {
+	rndr := NewRenderer("f", "x", false)
+
+	out := bytes.Buffer{}
+	rndr.Render(&out, locatedError{})
+	assert.Equal(t, "error[E0003]: located\n --> f:1:1\n  |\n1 | x\n  | ^\n", out.String())
+
+	out.Reset()
+	rndr.Render(&out, errors.New("plain"))
+	assert.Equal(t, "error: plain\n", out.String(), "Expecting errors without location to be printed as they are.")
+}
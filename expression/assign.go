@@ -0,0 +1,14 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Assign struct {
+	Name  scanner.Token
+	Value Expression
+}
+
+func (self Assign) Accept(visitor Visitor) interface{} {
+	return visitor.VisitAssign(self)
+}
@@ -0,0 +1,15 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Binary struct {
+	Left     Expression
+	Operator scanner.Token
+	Right    Expression
+}
+
+func (self Binary) Accept(visitor Visitor) interface{} {
+	return visitor.VisitBinary(self)
+}
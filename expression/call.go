@@ -0,0 +1,15 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Call struct {
+	Callee    Expression
+	Paren     scanner.Token
+	Arguments []Expression
+}
+
+func (self Call) Accept(visitor Visitor) interface{} {
+	return visitor.VisitCall(self)
+}
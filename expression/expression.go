@@ -0,0 +1,21 @@
+package expression
+
+type Visitor interface {
+	VisitBinary(expression Binary) interface{}
+	VisitGrouping(expression Grouping) interface{}
+	VisitLiteral(expression Literal) interface{}
+	VisitUnary(expression Unary) interface{}
+	VisitVariable(expression Variable) interface{}
+	VisitAssign(expression Assign) interface{}
+	VisitLogical(expression Logical) interface{}
+	VisitCall(expression Call) interface{}
+	VisitGet(expression Get) interface{}
+	VisitSet(expression Set) interface{}
+	VisitThis(expression This) interface{}
+	VisitSuper(expression Super) interface{}
+	VisitInterpolation(expression Interpolation) interface{}
+}
+
+type Expression interface {
+	Accept(Visitor) interface{}
+}
@@ -0,0 +1,14 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Get struct {
+	Object Expression
+	Name   scanner.Token
+}
+
+func (self Get) Accept(visitor Visitor) interface{} {
+	return visitor.VisitGet(self)
+}
@@ -0,0 +1,9 @@
+package expression
+
+type Grouping struct {
+	Expr Expression
+}
+
+func (self Grouping) Accept(visitor Visitor) interface{} {
+	return visitor.VisitGrouping(self)
+}
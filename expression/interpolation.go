@@ -0,0 +1,9 @@
+package expression
+
+type Interpolation struct {
+	Parts []Expression
+}
+
+func (self Interpolation) Accept(visitor Visitor) interface{} {
+	return visitor.VisitInterpolation(self)
+}
@@ -0,0 +1,13 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Literal struct {
+	Value scanner.Token
+}
+
+func (self Literal) Accept(visitor Visitor) interface{} {
+	return visitor.VisitLiteral(self)
+}
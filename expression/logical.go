@@ -0,0 +1,15 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Logical struct {
+	Left     Expression
+	Operator scanner.Token
+	Right    Expression
+}
+
+func (self Logical) Accept(visitor Visitor) interface{} {
+	return visitor.VisitLogical(self)
+}
@@ -0,0 +1,15 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Set struct {
+	Object Expression
+	Name   scanner.Token
+	Value  Expression
+}
+
+func (self Set) Accept(visitor Visitor) interface{} {
+	return visitor.VisitSet(self)
+}
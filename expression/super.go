@@ -0,0 +1,14 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Super struct {
+	Keyword scanner.Token
+	Method  scanner.Token
+}
+
+func (self Super) Accept(visitor Visitor) interface{} {
+	return visitor.VisitSuper(self)
+}
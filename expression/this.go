@@ -0,0 +1,13 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type This struct {
+	Keyword scanner.Token
+}
+
+func (self This) Accept(visitor Visitor) interface{} {
+	return visitor.VisitThis(self)
+}
@@ -0,0 +1,14 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Unary struct {
+	Operator scanner.Token
+	Right    Expression
+}
+
+func (self Unary) Accept(visitor Visitor) interface{} {
+	return visitor.VisitUnary(self)
+}
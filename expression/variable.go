@@ -0,0 +1,13 @@
+package expression
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Variable struct {
+	Name scanner.Token
+}
+
+func (self Variable) Accept(visitor Visitor) interface{} {
+	return visitor.VisitVariable(self)
+}
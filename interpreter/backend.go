@@ -0,0 +1,46 @@
+package interpreter
+
+import (
+	"errors"
+	"io"
+
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+	"github.com/th-lange/glox/vm"
+)
+
+const (
+	TREE_WALKER = "tree"
+	BYTECODE_VM = "vm"
+)
+
+// Backend executes the parsed and resolved statements.
+// The tree-walking Evaluator and the bytecode vm.VM are interchangeable behind it.
+type Backend interface {
+	Interpret(statements []statement.Stmt, locals map[scanner.Token]int) error
+	RegisterNative(name string, arity int, function func(arguments []interface{}) (interface{}, error))
+}
+
+// BackendOptions configure the debugging aids of the backends. Only the vm uses them,
+// the garbage collector stress test is rejected for the tree-walker instead of being ignored.
+type BackendOptions struct {
+	Debug    int8
+	GCStress bool
+}
+
+// NewBackend creates the execution engine with the given name, writing print output to out.
+func NewBackend(name string, out io.Writer, options BackendOptions) (Backend, error) {
+	switch name {
+	case TREE_WALKER:
+		if options.GCStress {
+			return nil, errors.New("The option gc-stress needs the '" + BYTECODE_VM + "' backend, the '" + TREE_WALKER + "' backend has no garbage collector.")
+		}
+		return NewEvaluator(out), nil
+	case BYTECODE_VM:
+		machine := vm.NewVM(out)
+		machine.Debug = options.Debug
+		machine.GCStress = options.GCStress
+		return machine, nil
+	}
+	return nil, errors.New("Unknown backend '" + name + "', expected '" + TREE_WALKER + "' or '" + BYTECODE_VM + "'.")
+}
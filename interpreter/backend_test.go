@@ -0,0 +1,119 @@
+package interpreter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/diagnostic"
+	"github.com/th-lange/glox/vm"
+)
+
+func TestNewBackend(t *testing.T) {
+	backend, err := NewBackend(TREE_WALKER, &bytes.Buffer{}, BackendOptions{})
+	assert.NoError(t, err)
+	assert.IsType(t, &Evaluator{}, backend)
+
+	backend, err = NewBackend(BYTECODE_VM, &bytes.Buffer{}, BackendOptions{})
+	assert.NoError(t, err)
+	assert.IsType(t, &vm.VM{}, backend)
+
+	_, err = NewBackend("jit", &bytes.Buffer{}, BackendOptions{})
+	assert.EqualError(t, err, "Unknown backend 'jit', expected 'tree' or 'vm'.")
+
+	_, err = NewBackend(TREE_WALKER, &bytes.Buffer{}, BackendOptions{GCStress: true})
+	assert.EqualError(t, err, "The option gc-stress needs the 'vm' backend, the 'tree' backend has no garbage collector.")
+}
+
+// runtimeErrorOf unifies the runtime errors of the backends to their diagnostic
+func runtimeErrorOf(t *testing.T, err error) diagnostic.Diagnostic {
+	switch runtimeError := err.(type) {
+	case RuntimeError:
+		return runtimeError.Diagnostic()
+	case vm.RuntimeError:
+		return runtimeError.Diagnostic()
+	}
+	t.Fatalf("Expecting a runtime error, got: %v", err)
+	return diagnostic.Diagnostic{}
+}
+
+func TestBackend_Interpret_RuntimeErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		output  string
+		line    int
+		message string
+	}{
+		{"print 1;\nprint \"a\" - 1;", "1\n", 2, "Operands must be numbers."},
+		{"print -true;", "", 1, "Operand must be a number."},
+		{"print 1 + nil;", "", 1, "Operands must be two numbers or two strings."},
+		{"print unknown;", "", 1, "Undefined variable 'unknown'."},
+		{"\n\nunknown = 1;", "", 3, "Undefined variable 'unknown'."},
+		{"\"not a function\"();", "", 1, "Can only call functions and classes."},
+		{"fun f(a, b) {}\nf(1);", "", 2, "Expected 2 arguments but got 1."},
+		{"clock(1);", "", 1, "Expected 0 arguments but got 1."},
+		{"class Foo {}\nFoo().bar;", "", 2, "Undefined property 'bar'."},
+		{"class Foo {}\nFoo().bar();", "", 2, "Undefined property 'bar'."},
+		{"var number = 1;\nnumber.field;", "", 2, "Only instances have properties."},
+		{"\"text\".method();", "", 1, "Only instances have properties."},
+		{"\"text\".field = 1;", "", 1, "Only instances have fields."},
+		{"class Point { init(x, y) {} }\nPoint(1);", "", 2, "Expected 2 arguments but got 1."},
+		{"class Empty {}\nEmpty(1);", "", 2, "Expected 0 arguments but got 1."},
+		{"var NotAClass = 1;\nclass Sub < NotAClass {}", "", 2, "Superclass must be a class."},
+		{"class Base {}\nclass Sub < Base {\n  m() { super.missing(); }\n}\nSub().m();", "", 3, "Undefined property 'missing'."},
+		{"fun f() {\n  print \"in f\";\n  return -nil;\n}\nf();", "in f\n", 3, "Operand must be a number."},
+	}
+
+	for _, tb := range backends {
+		for _, tt := range tests {
+			t.Run(tb.name+"/"+tt.source, func(t *testing.T) {
+				out := bytes.Buffer{}
+				diag := runtimeErrorOf(t, tb.create(t, &out).Interpret(parseProgram(t, tt.source)))
+				assert.Equal(t, tt.line, diag.Line, "Expecting the error to be reported at the correct line.")
+				assert.Equal(t, tt.message, diag.Message)
+				assert.Equal(t, tt.output, out.String(), "Expecting the execution to stop at the runtime error.")
+
+				tree := runtimeErrorOf(t, backends[0].create(t, &bytes.Buffer{}).Interpret(parseProgram(t, tt.source)))
+				assert.Equal(t, tree, diag, "Expecting all backends to underline the same span.")
+				assert.True(t, diag.Offset >= 0, "Expecting the position of the error to be known.")
+			})
+		}
+	}
+}
+
+func TestBackend_Interpret_KeepsGlobalsAfterRuntimeError(t *testing.T) {
+	for _, tb := range backends {
+		t.Run(tb.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			backend := tb.create(t, &out)
+
+			assert.NoError(t, backend.Interpret(parseProgram(t, "var a = \"global\";")))
+			assert.Error(t, backend.Interpret(parseProgram(t, "fun f() { var a = \"local\"; { print -a; } }\nf();")))
+			assert.NoError(t, backend.Interpret(parseProgram(t, "print a;")))
+			assert.Equal(t, "global\n", out.String(), "Expecting globals to survive a runtime error.")
+		})
+	}
+}
+
+func TestBackend_RegisterNative_UnsupportedResult(t *testing.T) {
+	for _, tb := range backends {
+		t.Run(tb.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			backend := tb.create(t, &out)
+			backend.RegisterNative("count", 0, func(arguments []interface{}) (interface{}, error) {
+				return 42, nil
+			})
+			backend.RegisterNative("same", 1, func(arguments []interface{}) (interface{}, error) {
+				return arguments[0], nil
+			})
+
+			assert.NoError(t, backend.Interpret(parseProgram(t, "class Foo {}\nprint same(Foo());\nprint same(nil);")))
+			assert.Equal(t, "Foo instance\nnil\n", out.String(), "Expecting lox values to be returned unchanged.")
+
+			diag := runtimeErrorOf(t, backend.Interpret(parseProgram(t, "print count();")))
+			assert.Equal(t, "Native returned unsupported type int.", diag.Message, "Expecting go types without a lox value to be rejected.")
+			assert.Equal(t, 12, diag.Offset, "Expecting the error at the closing parenthesis of the call.")
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package interpreter
+
+// Callable is implemented by everything, which can be called from lox code.
+// User defined functions and natives written in go share this call path.
+type Callable interface {
+	Arity() int
+	Call(evaluator *Evaluator, arguments []interface{}) (interface{}, error)
+}
@@ -0,0 +1,50 @@
+package interpreter
+
+// LoxClass is the runtime representation of a class declared in lox code.
+// Calling the class creates a new instance and runs the init method, if present.
+// Methods not found on the class itself are looked up through the superclass chain.
+type LoxClass struct {
+	name       string
+	superclass *LoxClass
+	methods    map[string]*LoxFunction
+}
+
+func NewLoxClass(name string, superclass *LoxClass, methods map[string]*LoxFunction) *LoxClass {
+	return &LoxClass{
+		name:       name,
+		superclass: superclass,
+		methods:    methods,
+	}
+}
+
+func (class *LoxClass) findMethod(name string) *LoxFunction {
+	if method, ok := class.methods[name]; ok {
+		return method
+	}
+	if class.superclass != nil {
+		return class.superclass.findMethod(name)
+	}
+	return nil
+}
+
+func (class *LoxClass) Arity() int {
+	if initializer := class.findMethod("init"); initializer != nil {
+		return initializer.Arity()
+	}
+	return 0
+}
+
+func (class *LoxClass) Call(evaluator *Evaluator, arguments []interface{}) (interface{}, error) {
+	instance := NewLoxInstance(class)
+	if initializer := class.findMethod("init"); initializer != nil {
+		_, err := initializer.Bind(instance).Call(evaluator, arguments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return instance, nil
+}
+
+func (class *LoxClass) String() string {
+	return class.name
+}
@@ -0,0 +1,69 @@
+package interpreter
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+// Environment stores the values of the declared variables by their name.
+// Variables, which are not found, are looked up in the enclosing environment.
+// The global environment has no enclosing environment.
+type Environment struct {
+	enclosing *Environment
+	values    map[string]interface{}
+}
+
+func NewEnvironment(enclosing *Environment) *Environment {
+	return &Environment{
+		enclosing: enclosing,
+		values:    make(map[string]interface{}),
+	}
+}
+
+// Define binds the value to the name. Redefining an existing variable is allowed.
+func (env *Environment) Define(name string, value interface{}) {
+	env.values[name] = value
+}
+
+func (env *Environment) Get(name scanner.Token) (interface{}, error) {
+	if value, ok := env.values[name.Lexeme]; ok {
+		return value, nil
+	}
+	if env.enclosing != nil {
+		return env.enclosing.Get(name)
+	}
+	return nil, undefinedVariable(name)
+}
+
+// Assign sets the value of an already defined variable.
+func (env *Environment) Assign(name scanner.Token, value interface{}) error {
+	if _, ok := env.values[name.Lexeme]; ok {
+		env.values[name.Lexeme] = value
+		return nil
+	}
+	if env.enclosing != nil {
+		return env.enclosing.Assign(name, value)
+	}
+	return undefinedVariable(name)
+}
+
+func undefinedVariable(name scanner.Token) RuntimeError {
+	return RuntimeError{name, "Undefined variable '" + name.Lexeme + "'."}
+}
+
+// GetAt returns the variable of the environment distance hops up the chain.
+// The distance is computed by the resolver, so the variable is known to exist.
+func (env *Environment) GetAt(distance int, name string) interface{} {
+	return env.ancestor(distance).values[name]
+}
+
+func (env *Environment) AssignAt(distance int, name string, value interface{}) {
+	env.ancestor(distance).values[name] = value
+}
+
+func (env *Environment) ancestor(distance int) *Environment {
+	environment := env
+	for i := 0; i < distance; i++ {
+		environment = environment.enclosing
+	}
+	return environment
+}
@@ -0,0 +1,78 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/scanner"
+)
+
+func TestEnvironment_DefineAndGet(t *testing.T) {
+	env := NewEnvironment(nil)
+	name := scanner.Token{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "foo"}
+
+	env.Define("foo", 1.0)
+	value, err := env.Get(name)
+	assert.NoError(t, err, "Expecting a defined variable to be found.")
+	assert.Equal(t, 1.0, value, "Expecting the defined value to be returned.")
+
+	env.Define("foo", "redefined")
+	value, _ = env.Get(name)
+	assert.Equal(t, "redefined", value, "Expecting a redefinition to overwrite the value.")
+
+	env.Define("bar", nil)
+	value, err = env.Get(scanner.Token{Lexeme: "bar"})
+	assert.NoError(t, err, "Expecting a variable defined as nil to be found.")
+	assert.Nil(t, value)
+}
+
+func TestEnvironment_Get_Undefined(t *testing.T) {
+	env := NewEnvironment(nil)
+	name := scanner.Token{Line: 3, Type: scanner.IDENTIFIER, Lexeme: "foo"}
+
+	_, err := env.Get(name)
+	assert.Equal(t, RuntimeError{name, "Undefined variable 'foo'."}, err, "Expecting a RuntimeError for undefined variables.")
+}
+
+func TestEnvironment_Assign(t *testing.T) {
+	env := NewEnvironment(nil)
+	name := scanner.Token{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "foo"}
+
+	err := env.Assign(name, 2.0)
+	assert.Equal(t, RuntimeError{name, "Undefined variable 'foo'."}, err, "Expecting assignments to undefined variables to fail.")
+
+	env.Define("foo", 1.0)
+	err = env.Assign(name, 2.0)
+	assert.NoError(t, err, "Expecting assignments to defined variables to succeed.")
+	value, _ := env.Get(name)
+	assert.Equal(t, 2.0, value, "Expecting the assigned value to be returned.")
+}
+
+func TestEnvironment_Enclosing(t *testing.T) {
+	outerName := scanner.Token{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "outer"}
+	innerName := scanner.Token{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "inner"}
+
+	global := NewEnvironment(nil)
+	global.Define("outer", 1.0)
+	local := NewEnvironment(global)
+	local.Define("inner", 2.0)
+
+	value, err := local.Get(outerName)
+	assert.NoError(t, err, "Expecting variables of the enclosing environment to be found.")
+	assert.Equal(t, 1.0, value)
+
+	err = local.Assign(outerName, 3.0)
+	assert.NoError(t, err, "Expecting variables of the enclosing environment to be assignable.")
+	value, _ = global.Get(outerName)
+	assert.Equal(t, 3.0, value, "Expecting the assignment to change the enclosing environment.")
+
+	_, err = global.Get(innerName)
+	assert.Error(t, err, "Expecting local variables not to leak into the enclosing environment.")
+
+	local.Define("outer", "shadowed")
+	value, _ = local.Get(outerName)
+	assert.Equal(t, "shadowed", value, "Expecting local variables to shadow the enclosing ones.")
+	value, _ = global.Get(outerName)
+	assert.Equal(t, 3.0, value, "Expecting shadowing not to change the enclosing environment.")
+}
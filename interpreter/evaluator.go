@@ -0,0 +1,408 @@
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/th-lange/glox/expression"
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+)
+
+// Evaluator is a tree-walking visitor, which executes the parsed statements.
+// Output of print statements is written to out.
+// Locals holds the scope depths of the local variables, as computed by the resolver.
+type Evaluator struct {
+	out         io.Writer
+	globals     *Environment
+	environment *Environment
+	locals      map[scanner.Token]int
+}
+
+func NewEvaluator(out io.Writer) *Evaluator {
+	globals := NewEnvironment(nil)
+	evaluator := &Evaluator{
+		out:         out,
+		globals:     globals,
+		environment: globals,
+		locals:      make(map[scanner.Token]int),
+	}
+	evaluator.DefineNative(NewNativeFunction("clock", 0, clock))
+	return evaluator
+}
+
+// DefineNative makes the native function available as global variable.
+func (evaluator *Evaluator) DefineNative(native *NativeFunction) {
+	evaluator.globals.Define(native.name, native)
+}
+
+// RegisterNative wraps the go function into a NativeFunction and defines it as global variable.
+func (evaluator *Evaluator) RegisterNative(name string, arity int, function func(arguments []interface{}) (interface{}, error)) {
+	evaluator.DefineNative(NewNativeFunction(name, arity, function))
+}
+
+// Interpret executes the resolved statements and reports a RuntimeError instead of panicking.
+func (evaluator *Evaluator) Interpret(statements []statement.Stmt, locals map[scanner.Token]int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			runtimeError, ok := r.(RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			err = runtimeError
+		}
+	}()
+	evaluator.locals = locals
+	for _, stmt := range statements {
+		evaluator.execute(stmt)
+	}
+	return nil
+}
+
+func (evaluator *Evaluator) execute(stmt statement.Stmt) {
+	stmt.Accept(evaluator)
+}
+
+func (evaluator *Evaluator) VisitExpressionStmt(stmt statement.Expression) interface{} {
+	evaluator.Evaluate(stmt.Expr)
+	return nil
+}
+
+func (evaluator *Evaluator) VisitPrintStmt(stmt statement.Print) interface{} {
+	value := evaluator.Evaluate(stmt.Expr)
+	fmt.Fprintln(evaluator.out, Stringify(value))
+	return nil
+}
+
+func (evaluator *Evaluator) VisitVarStmt(stmt statement.Var) interface{} {
+	var value interface{}
+	if stmt.Initializer != nil {
+		value = evaluator.Evaluate(stmt.Initializer)
+	}
+	evaluator.environment.Define(stmt.Name.Lexeme, value)
+	return nil
+}
+
+func (evaluator *Evaluator) VisitBlockStmt(stmt statement.Block) interface{} {
+	evaluator.executeBlock(stmt.Statements, NewEnvironment(evaluator.environment))
+	return nil
+}
+
+// executeBlock runs the statements within the given environment.
+// The previous environment is restored, even if a runtime error occurs.
+func (evaluator *Evaluator) executeBlock(statements []statement.Stmt, environment *Environment) {
+	previous := evaluator.environment
+	defer func() {
+		evaluator.environment = previous
+	}()
+
+	evaluator.environment = environment
+	for _, stmt := range statements {
+		evaluator.execute(stmt)
+	}
+}
+
+func (evaluator *Evaluator) VisitClassStmt(stmt statement.Class) interface{} {
+	var superclass *LoxClass
+	if stmt.Superclass != nil {
+		class, ok := evaluator.Evaluate(*stmt.Superclass).(*LoxClass)
+		if !ok {
+			panic(RuntimeError{stmt.Superclass.Name, "Superclass must be a class."})
+		}
+		superclass = class
+	}
+
+	environment := evaluator.environment
+	if superclass != nil {
+		// matches the extra scope the resolver opens for "super"
+		environment = NewEnvironment(environment)
+		environment.Define("super", superclass)
+	}
+
+	methods := make(map[string]*LoxFunction, len(stmt.Methods))
+	for _, method := range stmt.Methods {
+		methods[method.Name.Lexeme] = NewLoxFunction(method, environment, evaluator.locals, method.Name.Lexeme == "init")
+	}
+
+	evaluator.environment.Define(stmt.Name.Lexeme, NewLoxClass(stmt.Name.Lexeme, superclass, methods))
+	return nil
+}
+
+func (evaluator *Evaluator) VisitFunctionStmt(stmt statement.Function) interface{} {
+	evaluator.environment.Define(stmt.Name.Lexeme, NewLoxFunction(stmt, evaluator.environment, evaluator.locals, false))
+	return nil
+}
+
+func (evaluator *Evaluator) VisitReturnStmt(stmt statement.Return) interface{} {
+	var value interface{}
+	if stmt.Value != nil {
+		value = evaluator.Evaluate(stmt.Value)
+	}
+	panic(returnValue{value})
+}
+
+func (evaluator *Evaluator) VisitIfStmt(stmt statement.If) interface{} {
+	if isTruthy(evaluator.Evaluate(stmt.Condition)) {
+		evaluator.execute(stmt.ThenBranch)
+	} else if stmt.ElseBranch != nil {
+		evaluator.execute(stmt.ElseBranch)
+	}
+	return nil
+}
+
+func (evaluator *Evaluator) VisitWhileStmt(stmt statement.While) interface{} {
+	for isTruthy(evaluator.Evaluate(stmt.Condition)) {
+		evaluator.execute(stmt.Body)
+	}
+	return nil
+}
+
+func (evaluator *Evaluator) Evaluate(expr expression.Expression) interface{} {
+	return expr.Accept(evaluator)
+}
+
+func (evaluator *Evaluator) VisitBinary(expression expression.Binary) interface{} {
+	left := evaluator.Evaluate(expression.Left)
+	right := evaluator.Evaluate(expression.Right)
+
+	switch expression.Operator.Type {
+	case scanner.BANG_EQUAL:
+		return !isEqual(left, right)
+	case scanner.EQUAL_EQUAL:
+		return isEqual(left, right)
+	case scanner.GREATER:
+		l, r := checkNumberOperands(expression.Operator, left, right)
+		return l > r
+	case scanner.GREATER_EQUAL:
+		l, r := checkNumberOperands(expression.Operator, left, right)
+		return l >= r
+	case scanner.LESS:
+		l, r := checkNumberOperands(expression.Operator, left, right)
+		return l < r
+	case scanner.LESS_EQUAL:
+		l, r := checkNumberOperands(expression.Operator, left, right)
+		return l <= r
+	case scanner.MINUS:
+		l, r := checkNumberOperands(expression.Operator, left, right)
+		return l - r
+	case scanner.SLASH:
+		l, r := checkNumberOperands(expression.Operator, left, right)
+		return l / r
+	case scanner.STAR:
+		l, r := checkNumberOperands(expression.Operator, left, right)
+		return l * r
+	case scanner.PLUS:
+		if l, ok := left.(float64); ok {
+			if r, ok := right.(float64); ok {
+				return l + r
+			}
+		}
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r
+			}
+		}
+		panic(RuntimeError{expression.Operator, "Operands must be two numbers or two strings."})
+	}
+	return nil
+}
+
+func (evaluator *Evaluator) VisitGrouping(expression expression.Grouping) interface{} {
+	return evaluator.Evaluate(expression.Expr)
+}
+
+func (evaluator *Evaluator) VisitLiteral(expression expression.Literal) interface{} {
+	switch expression.Value.Type {
+	case scanner.TRUE:
+		return true
+	case scanner.FALSE:
+		return false
+	case scanner.NIL:
+		return nil
+	}
+	return expression.Value.Literal
+}
+
+func (evaluator *Evaluator) VisitUnary(expression expression.Unary) interface{} {
+	right := evaluator.Evaluate(expression.Right)
+
+	switch expression.Operator.Type {
+	case scanner.BANG:
+		return !isTruthy(right)
+	case scanner.MINUS:
+		return -checkNumberOperand(expression.Operator, right)
+	}
+	return nil
+}
+
+func (evaluator *Evaluator) VisitCall(expression expression.Call) interface{} {
+	callee := evaluator.Evaluate(expression.Callee)
+
+	arguments := make([]interface{}, 0, len(expression.Arguments))
+	for _, argument := range expression.Arguments {
+		arguments = append(arguments, evaluator.Evaluate(argument))
+	}
+
+	function, ok := callee.(Callable)
+	if !ok {
+		panic(RuntimeError{expression.Paren, "Can only call functions and classes."})
+	}
+	if len(arguments) != function.Arity() {
+		panic(RuntimeError{expression.Paren, "Expected " + strconv.Itoa(function.Arity()) + " arguments but got " + strconv.Itoa(len(arguments)) + "."})
+	}
+
+	result, err := function.Call(evaluator, arguments)
+	if err != nil {
+		if runtimeError, ok := err.(RuntimeError); ok {
+			panic(runtimeError)
+		}
+		panic(RuntimeError{expression.Paren, err.Error()})
+	}
+	return result
+}
+
+func (evaluator *Evaluator) VisitGet(expression expression.Get) interface{} {
+	object := evaluator.Evaluate(expression.Object)
+	instance, ok := object.(*LoxInstance)
+	if !ok {
+		panic(RuntimeError{expression.Name, "Only instances have properties."})
+	}
+	value, err := instance.Get(expression.Name)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (evaluator *Evaluator) VisitSet(expression expression.Set) interface{} {
+	object := evaluator.Evaluate(expression.Object)
+	instance, ok := object.(*LoxInstance)
+	if !ok {
+		panic(RuntimeError{expression.Name, "Only instances have fields."})
+	}
+	value := evaluator.Evaluate(expression.Value)
+	instance.Set(expression.Name, value)
+	return value
+}
+
+func (evaluator *Evaluator) VisitThis(expression expression.This) interface{} {
+	return evaluator.lookUpVariable(expression.Keyword)
+}
+
+// VisitSuper looks the method up on the superclass and binds it to the current instance.
+// The instance ("this") always lives in the scope right inside the one holding "super".
+func (evaluator *Evaluator) VisitSuper(expression expression.Super) interface{} {
+	distance := evaluator.locals[expression.Keyword]
+	superclass := evaluator.environment.GetAt(distance, "super").(*LoxClass)
+	instance := evaluator.environment.GetAt(distance-1, "this").(*LoxInstance)
+
+	method := superclass.findMethod(expression.Method.Lexeme)
+	if method == nil {
+		panic(RuntimeError{expression.Method, "Undefined property '" + expression.Method.Lexeme + "'."})
+	}
+	return method.Bind(instance)
+}
+
+// VisitInterpolation converts the embedded values like print does and joins them with the segments
+func (evaluator *Evaluator) VisitInterpolation(expression expression.Interpolation) interface{} {
+	sb := strings.Builder{}
+	for _, part := range expression.Parts {
+		sb.WriteString(Stringify(evaluator.Evaluate(part)))
+	}
+	return sb.String()
+}
+
+// VisitLogical short-circuits and returns the deciding operand instead of a bool
+func (evaluator *Evaluator) VisitLogical(expression expression.Logical) interface{} {
+	left := evaluator.Evaluate(expression.Left)
+
+	if expression.Operator.Type == scanner.OR {
+		if isTruthy(left) {
+			return left
+		}
+	} else if !isTruthy(left) {
+		return left
+	}
+	return evaluator.Evaluate(expression.Right)
+}
+
+func (evaluator *Evaluator) VisitVariable(expression expression.Variable) interface{} {
+	return evaluator.lookUpVariable(expression.Name)
+}
+
+func (evaluator *Evaluator) VisitAssign(expression expression.Assign) interface{} {
+	value := evaluator.Evaluate(expression.Value)
+	if distance, ok := evaluator.locals[expression.Name]; ok {
+		evaluator.environment.AssignAt(distance, expression.Name.Lexeme, value)
+		return value
+	}
+	err := evaluator.globals.Assign(expression.Name, value)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// lookUpVariable reads local variables from the resolved scope, all others from the globals
+func (evaluator *Evaluator) lookUpVariable(name scanner.Token) interface{} {
+	if distance, ok := evaluator.locals[name]; ok {
+		return evaluator.environment.GetAt(distance, name.Lexeme)
+	}
+	value, err := evaluator.globals.Get(name)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func checkNumberOperand(operator scanner.Token, operand interface{}) float64 {
+	if number, ok := operand.(float64); ok {
+		return number
+	}
+	panic(RuntimeError{operator, "Operand must be a number."})
+}
+
+func checkNumberOperands(operator scanner.Token, left, right interface{}) (float64, float64) {
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if lok && rok {
+		return l, r
+	}
+	panic(RuntimeError{operator, "Operands must be numbers."})
+}
+
+// nil and false are falsey, everything else is truthy
+func isTruthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	if boolean, ok := value.(bool); ok {
+		return boolean
+	}
+	return true
+}
+
+func isEqual(left, right interface{}) bool {
+	if left == nil && right == nil {
+		return true
+	}
+	if left == nil {
+		return false
+	}
+	return left == right
+}
+
+// Stringify renders a runtime value the way lox prints it.
+func Stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
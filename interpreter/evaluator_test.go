@@ -0,0 +1,863 @@
+package interpreter
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/expression"
+	"github.com/th-lange/glox/optimizer"
+	"github.com/th-lange/glox/parser"
+	"github.com/th-lange/glox/resolver"
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+)
+
+// parseProgram scans, parses and resolves the source. The result can be passed to Evaluator.Interpret directly.
+func parseProgram(t *testing.T, source string) ([]statement.Stmt, map[scanner.Token]int) {
+	scnr := scanner.Scanner{}
+	scnr.Scan(source)
+	assert.False(t, scnr.HadError, "Expecting the source to be scanned without errors: "+source)
+	statements := parser.NewParser(&scnr.Tokens).Parse()
+	assert.NotNil(t, statements, "Expecting the source to be parsed without errors: "+source)
+	rslvr := resolver.NewResolver()
+	rslvr.Resolve(statements)
+	assert.Empty(t, rslvr.Errors, "Expecting the source to be resolved without errors: "+source)
+	return statements, rslvr.Locals
+}
+
+func parseExpression(t *testing.T, source string) expression.Expression {
+	statements, _ := parseProgram(t, source+";")
+	return statements[0].(statement.Expression).Expr
+}
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected interface{}
+	}{
+		{"1 + 2 * 3", 7.0},
+		{"(1 + 2) * 3", 9.0},
+		{"10 / 4", 2.5},
+		{"8 - 3 - 2", 3.0},
+		{"-(2 + 3)", -5.0},
+		{"\"foo\" + \"bar\"", "foobar"},
+		{"1 < 2", true},
+		{"2 <= 1", false},
+		{"3 > 2", true},
+		{"3 >= 4", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"nil == nil", true},
+		{"nil == false", false},
+		{"1 == \"1\"", false},
+		{"\"a\" == \"a\"", true},
+		{"!nil", true},
+		{"!0", false},
+		{"!!\"\"", true},
+		{"true", true},
+		{"nil", nil},
+	}
+
+	evaluator := NewEvaluator(&bytes.Buffer{})
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			result := evaluator.Evaluate(parseExpression(t, tt.source))
+			assert.Equal(t, tt.expected, result, "Expecting the correct value for: "+tt.source)
+		})
+	}
+}
+
+func TestIsTruthy(t *testing.T) {
+	assert.False(t, isTruthy(nil), "Expecting nil to be falsey")
+	assert.False(t, isTruthy(false), "Expecting false to be falsey")
+	assert.True(t, isTruthy(true), "Expecting true to be truthy")
+	assert.True(t, isTruthy(0.0), "Expecting 0 to be truthy")
+	assert.True(t, isTruthy(""), "Expecting the empty string to be truthy")
+}
+
+func TestStringify(t *testing.T) {
+	assert.Equal(t, "nil", Stringify(nil))
+	assert.Equal(t, "true", Stringify(true))
+	assert.Equal(t, "7", Stringify(7.0))
+	assert.Equal(t, "2.5", Stringify(2.5))
+	assert.Equal(t, "-0.125", Stringify(-0.125))
+	assert.Equal(t, "text", Stringify("text"))
+}
+
+func TestEvaluator_Interpret_RuntimeError(t *testing.T) {
+	tests := []struct {
+		source   string
+		lexeme   string
+		position int
+		message  string
+	}{
+		{"\"a\" - 1", "-", 4, "Operands must be numbers."},
+		{"-true", "-", 0, "Operand must be a number."},
+		{"1 + nil", "+", 2, "Operands must be two numbers or two strings."},
+		{"(1 < \"b\")", "<", 3, "Operands must be numbers."},
+	}
+
+	out := bytes.Buffer{}
+	evaluator := NewEvaluator(&out)
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			err := evaluator.Interpret(parseProgram(t, "print "+tt.source+";"))
+			assert.Empty(t, out.String(), "Expecting no output if a runtime error occurs.")
+			if assert.IsType(t, RuntimeError{}, err, "Expecting a RuntimeError for: "+tt.source) {
+				runtimeError := err.(RuntimeError)
+				assert.Equal(t, tt.lexeme, runtimeError.Token.Lexeme)
+				assert.Equal(t, 1, runtimeError.Token.Line)
+				assert.Equal(t, len("print ")+tt.position, runtimeError.Token.Position)
+				assert.Equal(t, tt.message, runtimeError.Message)
+			}
+		})
+	}
+}
+
+func TestEvaluator_Interpret_Print(t *testing.T) {
+	out := bytes.Buffer{}
+	evaluator := NewEvaluator(&out)
+
+	err := evaluator.Interpret(parseProgram(t, "print 1 + 2 * 3;\n\"no output\";\nprint \"a\" + \"b\";\nprint nil;"))
+	assert.NoError(t, err, "Expecting the statements to be executed without errors.")
+	assert.Equal(t, "7\nab\nnil\n", out.String(), "Expecting only print statements to write output.")
+}
+
+func TestEvaluator_Interpret_StopsAtRuntimeError(t *testing.T) {
+	out := bytes.Buffer{}
+	evaluator := NewEvaluator(&out)
+
+	err := evaluator.Interpret(parseProgram(t, "print 1;\nprint -\"one\";\nprint 2;"))
+	assert.Error(t, err, "Expecting the runtime error to be reported.")
+	assert.Equal(t, "1\n", out.String(), "Expecting the execution to stop at the runtime error.")
+}
+
+func TestEvaluator_Interpret_Variables(t *testing.T) {
+	out := bytes.Buffer{}
+	evaluator := NewEvaluator(&out)
+
+	err := evaluator.Interpret(parseProgram(t, "var x = 1;\nx = x + 1;\nprint x;\nvar y;\nprint y;\nprint y = x = 5;\nprint x;"))
+	assert.NoError(t, err, "Expecting the statements to be executed without errors.")
+	assert.Equal(t, "2\nnil\n5\n5\n", out.String())
+}
+
+func TestEvaluator_Interpret_Unicode(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name: "Unicode identifiers and strings",
+			source: `var größe = "¡olé! 🎉";
+var 名前 = größe + " ✓";
+print 名前;`,
+			expected: "¡olé! 🎉 ✓\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_Numbers(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name:     "Numeric literals",
+			source:   `print 0x1F + 0b1010; print 1_000_000; print 2.5e2; print 1e-3;`,
+			expected: "41\n1000000\n250\n0.001\n",
+		},
+		{
+			name:     "Negative zero",
+			source:   `print 0; print -0; print 0 * -1; print "${-0}";`,
+			expected: "0\n-0\n-0\n-0\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_Strings(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name:     "Escape sequences",
+			source:   `print "tab\there\n\"quoted\" \\ \u{2713}";`,
+			expected: "tab\there\n\"quoted\" \\ ✓\n",
+		},
+		{
+			name: "Interpolation",
+			source: `var name = "lox";
+fun shout(text) { return text + "!"; }
+print "Hello ${name}, ${shout("hey ${name}")} ${"${""}"}end";`,
+			expected: "Hello lox, hey lox! end\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_InterpolationOfValues(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name:     "Number",
+			source:   `print "a ${1}";`,
+			expected: "a 1\n",
+		},
+		{
+			name: "Values of every kind",
+			source: `class Point {}
+fun f() {}
+var x = 2.5;
+print "${nil} ${true} ${x * 2} ${-x} ${Point} ${Point()} ${f} ${clock == nil}";`,
+			expected: "nil true 5 -2.5 Point Point instance <fn f> false\n",
+		},
+		{
+			name:     "Only an embedded value",
+			source:   `var n = 3; print "${n}" + "${n + 1}";`,
+			expected: "34\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_UndefinedVariable(t *testing.T) {
+	evaluator := NewEvaluator(&bytes.Buffer{})
+
+	err := evaluator.Interpret(parseProgram(t, "print unknown;"))
+	assert.EqualError(t, err, "[Line 1] RuntimeError at 'unknown' (Column 7): Undefined variable 'unknown'.")
+
+	err = evaluator.Interpret(parseProgram(t, "unknown = 1;"))
+	assert.EqualError(t, err, "[Line 1] RuntimeError at 'unknown' (Column 1): Undefined variable 'unknown'.")
+}
+
+type GoldenTest struct {
+	name     string
+	source   string
+	expected string
+}
+
+// testBackend is an execution engine with its options, every golden test has to pass on each of them.
+// The gc stress run collects on every allocation, to reveal objects freed while still in use.
+type testBackend struct {
+	name    string
+	backend string
+	options BackendOptions
+}
+
+var backends = []testBackend{
+	{name: "tree", backend: TREE_WALKER},
+	{name: "vm", backend: BYTECODE_VM},
+	{name: "vm-gc-stress", backend: BYTECODE_VM, options: BackendOptions{GCStress: true}},
+}
+
+func (tb testBackend) create(t *testing.T, out io.Writer) Backend {
+	backend, err := NewBackend(tb.backend, out, tb.options)
+	assert.NoError(t, err)
+	return backend
+}
+
+// runGoldenTests runs every test on all backends, once as parsed and once optimized.
+// Both runs have to print the same output.
+func runGoldenTests(t *testing.T, tests []GoldenTest) {
+	for _, tb := range backends {
+		for _, tt := range tests {
+			t.Run(tb.name+"/"+tt.name, func(t *testing.T) {
+				out := bytes.Buffer{}
+				err := tb.create(t, &out).Interpret(parseProgram(t, tt.source))
+				assert.NoError(t, err, "Expecting no runtime error for: "+tt.name)
+				assert.Equal(t, tt.expected, out.String(), "Expecting the correct output for: "+tt.name)
+			})
+			t.Run(tb.name+"-O/"+tt.name, func(t *testing.T) {
+				out := bytes.Buffer{}
+				statements, locals := parseProgram(t, tt.source)
+				err := tb.create(t, &out).Interpret(optimizer.Optimize(statements), locals)
+				assert.NoError(t, err, "Expecting no runtime error for: "+tt.name)
+				assert.Equal(t, tt.expected, out.String(), "Expecting the optimized program to print the same output for: "+tt.name)
+			})
+		}
+	}
+}
+
+func TestEvaluator_Interpret_Blocks(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name: "Shadowing",
+			source: `var a = "global";
+{
+  var a = "outer";
+  {
+    var a = "inner";
+    print a;
+  }
+  print a;
+}
+print a;`,
+			expected: "inner\nouter\nglobal\n",
+		},
+		{
+			name: "Assignment to outer variable",
+			source: `var a = 1;
+{
+  a = a + 1;
+  {
+    a = a * 10;
+  }
+}
+print a;`,
+			expected: "20\n",
+		},
+		{
+			name: "Empty block",
+			source: `{}
+print "after";`,
+			expected: "after\n",
+		},
+		{
+			name: "Scope from the lox reference",
+			source: `var a = "global a";
+var b = "global b";
+var c = "global c";
+{
+  var a = "outer a";
+  var b = "outer b";
+  {
+    var a = "inner a";
+    print a;
+    print b;
+    print c;
+  }
+  print a;
+  print b;
+  print c;
+}
+print a;
+print b;
+print c;`,
+			expected: "inner a\nouter b\nglobal c\nouter a\nouter b\nglobal c\nglobal a\nglobal b\nglobal c\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_LocalVariablesEndWithBlock(t *testing.T) {
+	evaluator := NewEvaluator(&bytes.Buffer{})
+
+	err := evaluator.Interpret(parseProgram(t, "{ var local = 1; }\nprint local;"))
+	assert.EqualError(t, err, "[Line 2] RuntimeError at 'local' (Column 7): Undefined variable 'local'.")
+}
+
+func TestEvaluator_Interpret_RestoresScopeAfterRuntimeError(t *testing.T) {
+	out := bytes.Buffer{}
+	evaluator := NewEvaluator(&out)
+
+	err := evaluator.Interpret(parseProgram(t, "var a = \"global\";\n{\n  var a = \"local\";\n  {\n    print -a;\n  }\n}"))
+	assert.Error(t, err, "Expecting the runtime error inside the block to be reported.")
+	assert.Nil(t, evaluator.environment.enclosing, "Expecting the global environment to be restored.")
+
+	err = evaluator.Interpret(parseProgram(t, "print a;"))
+	assert.NoError(t, err)
+	assert.Equal(t, "global\n", out.String(), "Expecting the outer scope to be active after the runtime error.")
+}
+
+func TestEvaluator_Interpret_ControlFlow(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name: "If else",
+			source: `if (1 < 2) print "then"; else print "else";
+if (nil) print "then"; else print "else";
+if (false) print "skipped";
+if (0) { print "zero is truthy"; }`,
+			expected: "then\nelse\nzero is truthy\n",
+		},
+		{
+			name:     "Dangling else binds to the nearest if",
+			source:   `if (true) if (false) print "inner then"; else print "inner else";`,
+			expected: "inner else\n",
+		},
+		{
+			name: "While",
+			source: `var i = 0;
+while (i < 3) {
+  print i;
+  i = i + 1;
+}`,
+			expected: "0\n1\n2\n",
+		},
+		{
+			name: "For",
+			source: `for (var i = 0; i < 3; i = i + 1) print i;
+var j = 10;
+for (; j > 8;) j = j - 1;
+print j;`,
+			expected: "0\n1\n2\n8\n",
+		},
+		{
+			name: "For loop variable is scoped to the loop",
+			source: `var i = "outer";
+for (var i = 0; i < 1; i = i + 1) {}
+print i;`,
+			expected: "outer\n",
+		},
+		{
+			name: "Fibonacci",
+			source: `var a = 0;
+var temp;
+for (var b = 1; a < 100; b = temp + b) {
+  print a;
+  temp = a;
+  a = b;
+}`,
+			expected: "0\n1\n1\n2\n3\n5\n8\n13\n21\n34\n55\n89\n",
+		},
+		{
+			name: "Logical operators return operands",
+			source: `print "hi" or 2;
+print nil or "yes";
+print false or nil;
+print nil and "no";
+print 1 and 2;
+print 1 == 1 and 2 == 3 or "fallback";`,
+			expected: "hi\nyes\nnil\nnil\n2\nfallback\n",
+		},
+		{
+			name: "Logical operators short-circuit",
+			source: `var a = "unchanged";
+false and (a = "changed");
+true or (a = "changed");
+print a;
+true and (a = "changed");
+print a;`,
+			expected: "unchanged\nchanged\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_Functions(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name: "Declaration and call",
+			source: `fun sayHi(first, last) {
+  print "Hi, " + first + " " + last + "!";
+}
+sayHi("Dear", "Reader");`,
+			expected: "Hi, Dear Reader!\n",
+		},
+		{
+			name: "Return values",
+			source: `fun add(a, b) { return a + b; }
+fun nothing() { return; }
+fun noReturn() {}
+print add(1, 2);
+print nothing();
+print noReturn();`,
+			expected: "3\nnil\nnil\n",
+		},
+		{
+			name: "Return leaves loops early",
+			source: `fun firstAbove(limit) {
+  for (var i = 0; i < 100; i = i + 1) {
+    if (i > limit) return i;
+  }
+}
+print firstAbove(3);`,
+			expected: "4\n",
+		},
+		{
+			name: "Recursion",
+			source: `fun fib(n) {
+  if (n < 2) return n;
+  return fib(n - 1) + fib(n - 2);
+}
+for (var i = 0; i < 10; i = i + 1) print fib(i);`,
+			expected: "0\n1\n1\n2\n3\n5\n8\n13\n21\n34\n",
+		},
+		{
+			name: "Functions are first class",
+			source: `fun twice(value) { return value * 2; }
+fun apply(function, value) { return function(value); }
+var alias = twice;
+print apply(alias, 21);
+print twice;
+print clock;`,
+			expected: "42\n<fn twice>\n<native fn clock>\n",
+		},
+		{
+			name: "Calls can be chained",
+			source: `fun outer() {
+  fun inner() { return "inner"; }
+  return inner;
+}
+print outer()();`,
+			expected: "inner\n",
+		},
+		{
+			name: "Native clock",
+			source: `var start = clock();
+print start > 0;
+print clock() >= start;`,
+			expected: "true\ntrue\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_CallErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		message string
+	}{
+		{"\"not a function\"();", "Can only call functions and classes."},
+		{"fun f(a, b) {}\nf(1);", "Expected 2 arguments but got 1."},
+		{"clock(1);", "Expected 0 arguments but got 1."},
+		{"fun f() { return -nil; }\nf();", "Operand must be a number."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			evaluator := NewEvaluator(&bytes.Buffer{})
+			err := evaluator.Interpret(parseProgram(t, tt.source))
+			if assert.IsType(t, RuntimeError{}, err, "Expecting a RuntimeError for: "+tt.source) {
+				assert.Equal(t, tt.message, err.(RuntimeError).Message)
+			}
+			assert.Equal(t, evaluator.globals, evaluator.environment, "Expecting the global environment to be restored.")
+		})
+	}
+}
+
+func TestEvaluator_Interpret_Closures(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name: "Counter",
+			source: `fun makeCounter() {
+  var i = 0;
+  fun count() {
+    i = i + 1;
+    print i;
+  }
+  return count;
+}
+var counter = makeCounter();
+counter();
+counter();
+var other = makeCounter();
+other();`,
+			expected: "1\n2\n1\n",
+		},
+		{
+			name: "Closures bind the variable of their declaration",
+			source: `var a = "global";
+{
+  fun showA() {
+    print a;
+  }
+  showA();
+  var a = "block";
+  showA();
+  print a;
+}`,
+			expected: "global\nglobal\nblock\n",
+		},
+		{
+			name: "Closures share the captured variable",
+			source: `var get;
+var set;
+{
+  var shared = "initial";
+  fun getter() { return shared; }
+  fun setter(value) { shared = value; }
+  get = getter;
+  set = setter;
+}
+set("updated");
+print get();`,
+			expected: "updated\n",
+		},
+		{
+			name: "Nested closures",
+			source: `fun outer() {
+  var x = "outer";
+  fun middle() {
+    fun inner() {
+      print x;
+    }
+    return inner;
+  }
+  return middle;
+}
+outer()()();`,
+			expected: "outer\n",
+		},
+		{
+			name: "Local recursive function",
+			source: `{
+  fun countdown(n) {
+    if (n > 0) {
+      print n;
+      countdown(n - 1);
+    }
+  }
+  countdown(2);
+}`,
+			expected: "2\n1\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_ClosuresAcrossPrograms(t *testing.T) {
+	out := bytes.Buffer{}
+	evaluator := NewEvaluator(&out)
+
+	err := evaluator.Interpret(parseProgram(t, "fun f() { var a = \"inner\"; { print a; } }"))
+	assert.NoError(t, err)
+	// the second a is read by a token equal to the one within f, but resolves to the global
+	err = evaluator.Interpret(parseProgram(t, "var a = \"global\";          { print a; } f();"))
+	assert.NoError(t, err)
+	assert.Equal(t, "global\ninner\n", out.String(), "Expecting functions to keep the resolution of their own program.")
+}
+
+func TestEvaluator_Interpret_Classes(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name: "Class and instance",
+			source: `class Bagel {}
+var bagel = Bagel();
+print Bagel;
+print bagel;`,
+			expected: "Bagel\nBagel instance\n",
+		},
+		{
+			name: "Fields",
+			source: `class Box {}
+var box = Box();
+box.content = "cake";
+print box.content;
+box.content = box.content + "!";
+print box.content;
+print box.other = 1;`,
+			expected: "cake\ncake!\n1\n",
+		},
+		{
+			name: "Methods and this",
+			source: `class Cake {
+  taste() {
+    var adjective = "delicious";
+    print "The " + this.flavor + " cake is " + adjective + "!";
+  }
+}
+var cake = Cake();
+cake.flavor = "German chocolate";
+cake.taste();`,
+			expected: "The German chocolate cake is delicious!\n",
+		},
+		{
+			name: "Bound methods remember their instance",
+			source: `class Person {
+  sayName() { print this.name; }
+}
+var jane = Person();
+jane.name = "Jane";
+var bill = Person();
+bill.name = "Bill";
+bill.sayName = jane.sayName;
+bill.sayName();`,
+			expected: "Jane\n",
+		},
+		{
+			name: "This in closures",
+			source: `class Thing {
+  getCallback() {
+    fun localFunction() {
+      print this.name;
+    }
+    return localFunction;
+  }
+}
+var thing = Thing();
+thing.name = "thing";
+var callback = thing.getCallback();
+callback();`,
+			expected: "thing\n",
+		},
+		{
+			name: "Initializer",
+			source: `class Point {
+  init(x, y) {
+    this.x = x;
+    this.y = y;
+  }
+  sum() { return this.x + this.y; }
+}
+var point = Point(1, 2);
+print point.sum();`,
+			expected: "3\n",
+		},
+		{
+			name: "Initializer returns this",
+			source: `class Foo {
+  init() {
+    this.count = 0;
+    if (true) return;
+    this.count = 100;
+  }
+}
+var foo = Foo();
+print foo.count;
+foo.count = 5;
+print foo.init();
+print foo.count;`,
+			expected: "0\nFoo instance\n0\n",
+		},
+		{
+			name: "Fields shadow methods",
+			source: `class Shadow {
+  value() { return "method"; }
+}
+var shadow = Shadow();
+print shadow.value();
+shadow.value = "field";
+print shadow.value;`,
+			expected: "method\nfield\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_ClassErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		message string
+	}{
+		{"class Foo {}\nFoo().bar;", "Undefined property 'bar'."},
+		{"var number = 1;\nnumber.field;", "Only instances have properties."},
+		{"\"text\".field = 1;", "Only instances have fields."},
+		{"class Point { init(x, y) {} }\nPoint(1);", "Expected 2 arguments but got 1."},
+		{"class Empty {}\nEmpty(1);", "Expected 0 arguments but got 1."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			evaluator := NewEvaluator(&bytes.Buffer{})
+			err := evaluator.Interpret(parseProgram(t, tt.source))
+			if assert.IsType(t, RuntimeError{}, err, "Expecting a RuntimeError for: "+tt.source) {
+				assert.Equal(t, tt.message, err.(RuntimeError).Message)
+			}
+		})
+	}
+}
+
+func TestEvaluator_Interpret_Inheritance(t *testing.T) {
+	runGoldenTests(t, []GoldenTest{
+		{
+			name: "Inherited methods",
+			source: `class Doughnut {
+  cook() { print "Fry until golden brown."; }
+}
+class BostonCream < Doughnut {}
+BostonCream().cook();`,
+			expected: "Fry until golden brown.\n",
+		},
+		{
+			name: "Super calls",
+			source: `class Doughnut {
+  cook() { print "Fry until golden brown."; }
+}
+class BostonCream < Doughnut {
+  cook() {
+    super.cook();
+    print "Pipe full of custard and coat with chocolate.";
+  }
+}
+BostonCream().cook();`,
+			expected: "Fry until golden brown.\nPipe full of custard and coat with chocolate.\n",
+		},
+		{
+			name: "Three level hierarchy",
+			source: `class A {
+  method() { print "A method"; }
+  name() { return "A"; }
+}
+class B < A {
+  method() { print "B method"; }
+  test() { super.method(); }
+  name() { return "B<" + super.name(); }
+}
+class C < B {
+  name() { return "C<" + super.name(); }
+}
+C().test();
+C().method();
+print C().name();`,
+			expected: "A method\nB method\nC<B<A\n",
+		},
+		{
+			name: "Inherited initializer",
+			source: `class Base {
+  init(value) { this.value = value; }
+}
+class Derived < Base {
+  init(value) {
+    super.init(value * 2);
+    this.extra = value;
+  }
+}
+class Leaf < Derived {}
+var leaf = Leaf(21);
+print leaf.value;
+print leaf.extra;`,
+			expected: "42\n21\n",
+		},
+		{
+			name: "Super inside closures",
+			source: `class Base {
+  greet() { return "Hello from " + this.name; }
+}
+class Derived < Base {
+  init(name) { this.name = name; }
+  greeter() {
+    fun greet() {
+      fun inner() { return super.greet(); }
+      return inner();
+    }
+    return greet;
+  }
+  greet() { return "overridden"; }
+}
+var greeter = Derived("derived").greeter();
+print greeter();`,
+			expected: "Hello from derived\n",
+		},
+		{
+			name: "Super is bound statically",
+			source: `class A {
+  method() { print "A"; }
+}
+class B < A {
+  method() { print "B"; }
+  test() { super.method(); }
+}
+class C < B {}
+C().test();`,
+			expected: "A\n",
+		},
+		{
+			name: "Local classes",
+			source: `{
+  class A { name() { return "A"; } }
+  class B < A { name() { return "B" + super.name(); } }
+  print B().name();
+}`,
+			expected: "BA\n",
+		},
+	})
+}
+
+func TestEvaluator_Interpret_InheritanceErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		lexeme  string
+		message string
+	}{
+		{"var NotAClass = \"so not a class\";\nclass Sub < NotAClass {}", "NotAClass", "Superclass must be a class."},
+		{"fun f() {}\nclass Sub < f {}", "f", "Superclass must be a class."},
+		{"class Base {}\nclass Sub < Base { m() { super.missing(); } }\nSub().m();", "missing", "Undefined property 'missing'."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			evaluator := NewEvaluator(&bytes.Buffer{})
+			err := evaluator.Interpret(parseProgram(t, tt.source))
+			if assert.IsType(t, RuntimeError{}, err, "Expecting a RuntimeError for: "+tt.source) {
+				assert.Equal(t, tt.lexeme, err.(RuntimeError).Token.Lexeme)
+				assert.Equal(t, tt.message, err.(RuntimeError).Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package interpreter
+
+import (
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+)
+
+// LoxFunction is the runtime representation of a function declared in lox code.
+// It captures the environment of its declaration (closure) and the resolved
+// scope depths of the program it was declared in.
+// Initializers always return the instance they are bound to.
+type LoxFunction struct {
+	declaration   statement.Function
+	closure       *Environment
+	locals        map[scanner.Token]int
+	isInitializer bool
+}
+
+func NewLoxFunction(declaration statement.Function, closure *Environment, locals map[scanner.Token]int, isInitializer bool) *LoxFunction {
+	return &LoxFunction{
+		declaration:   declaration,
+		closure:       closure,
+		locals:        locals,
+		isInitializer: isInitializer,
+	}
+}
+
+// Bind returns a copy of the method, which has "this" defined as the given instance.
+func (fn *LoxFunction) Bind(instance *LoxInstance) *LoxFunction {
+	environment := NewEnvironment(fn.closure)
+	environment.Define("this", instance)
+	return NewLoxFunction(fn.declaration, environment, fn.locals, fn.isInitializer)
+}
+
+func (fn *LoxFunction) Arity() int {
+	return len(fn.declaration.Params)
+}
+
+// Call executes the body in a new environment holding the arguments.
+// A return statement unwinds the body with a returnValue panic, which ends the call.
+func (fn *LoxFunction) Call(evaluator *Evaluator, arguments []interface{}) (result interface{}, err error) {
+	environment := NewEnvironment(fn.closure)
+	for i, param := range fn.declaration.Params {
+		environment.Define(param.Lexeme, arguments[i])
+	}
+
+	callerLocals := evaluator.locals
+	evaluator.locals = fn.locals
+	defer func() {
+		evaluator.locals = callerLocals
+		if r := recover(); r != nil {
+			value, ok := r.(returnValue)
+			if !ok {
+				panic(r)
+			}
+			result = value.value
+			if fn.isInitializer {
+				result = fn.closure.GetAt(0, "this")
+			}
+		}
+	}()
+	evaluator.executeBlock(fn.declaration.Body, environment)
+	if fn.isInitializer {
+		return fn.closure.GetAt(0, "this"), nil
+	}
+	return nil, nil
+}
+
+func (fn *LoxFunction) String() string {
+	return "<fn " + fn.declaration.Name.Lexeme + ">"
+}
+
+// returnValue carries the value of a return statement up to the calling function
+type returnValue struct {
+	value interface{}
+}
@@ -0,0 +1,38 @@
+package interpreter
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+// LoxInstance is an object created by calling a LoxClass.
+type LoxInstance struct {
+	class  *LoxClass
+	fields map[string]interface{}
+}
+
+func NewLoxInstance(class *LoxClass) *LoxInstance {
+	return &LoxInstance{
+		class:  class,
+		fields: make(map[string]interface{}),
+	}
+}
+
+// Get returns the field of the given name. Fields shadow methods.
+// Methods are returned bound to the instance.
+func (instance *LoxInstance) Get(name scanner.Token) (interface{}, error) {
+	if value, ok := instance.fields[name.Lexeme]; ok {
+		return value, nil
+	}
+	if method := instance.class.findMethod(name.Lexeme); method != nil {
+		return method.Bind(instance), nil
+	}
+	return nil, RuntimeError{name, "Undefined property '" + name.Lexeme + "'."}
+}
+
+func (instance *LoxInstance) Set(name scanner.Token, value interface{}) {
+	instance.fields[name.Lexeme] = value
+}
+
+func (instance *LoxInstance) String() string {
+	return instance.class.name + " instance"
+}
@@ -3,39 +3,160 @@ package interpreter
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/th-lange/glox/statusCodes"
 
+	"github.com/th-lange/glox/compiler"
+	"github.com/th-lange/glox/diagnostic"
+	"github.com/th-lange/glox/optimizer"
+	"github.com/th-lange/glox/parser"
+	"github.com/th-lange/glox/resolver"
 	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+	"github.com/th-lange/glox/vm"
 )
 
+// BYTECODE_EXTENSION marks files written by "glox compile", RunFiles executes them on the vm.
+const BYTECODE_EXTENSION = ".gloxc"
+
 type Interpreter struct {
 	Scnr         scanner.Scanner
+	Backend      Backend
 	IgnoreErrors bool
+	// Optimize folds constants and removes dead code before the statements are executed or compiled
+	Optimize bool
+	// BackendOptions are the debugging aids of the command line, applied to the vm running bytecode files
+	BackendOptions BackendOptions
+	replMode       bool
+	// file and source of the running script, errors are reported with an excerpt of it
+	file   string
+	source string
 }
 
 func Init(debug int8) Interpreter {
 	return Interpreter{
 		Scnr:         scanner.Scanner{Debug: debug},
+		Backend:      NewEvaluator(os.Stdout),
 		IgnoreErrors: false,
 	}
 }
 
+// RegisterNative exposes a go function to the lox scripts as global function.
+// Errors returned by the function are reported as runtime errors of the script.
+func (intp *Interpreter) RegisterNative(name string, arity int, function func(arguments []interface{}) (interface{}, error)) {
+	intp.Backend.RegisterNative(name, arity, function)
+}
+
 func (intp *Interpreter) BreakOnError(isTrue bool) {
 	intp.IgnoreErrors = isTrue
 }
 
 func (intp *Interpreter) run(lines string) {
+	statements, locals, ok := intp.analyze(lines)
+	if !ok {
+		return
+	}
+
+	err := intp.Backend.Interpret(statements, locals)
+	if err != nil {
+		intp.report(err)
+		if !intp.IgnoreErrors {
+			os.Exit(statusCodes.EXIT_RUNTIME_ERROR)
+		}
+	}
+}
+
+// analyze scans, parses, resolves and optionally optimizes the source. Errors are printed and end the program, unless they are ignored.
+func (intp *Interpreter) analyze(lines string) ([]statement.Stmt, map[scanner.Token]int, bool) {
+	intp.source = lines
 	intp.runScanner(lines)
+	if intp.Scnr.HadError {
+		return nil, nil, false
+	}
+
+	prs := parser.NewParser(&intp.Scnr.Tokens)
+	if intp.replMode {
+		prs.EnableReplMode()
+	}
+	statements := prs.Parse()
+	if statements == nil {
+		for _, err := range prs.Errors() {
+			intp.report(err)
+		}
+		if !intp.IgnoreErrors {
+			os.Exit(statusCodes.EXIT_DATA_ERROR)
+		}
+		return nil, nil, false
+	}
+	return intp.resolve(statements)
+}
+
+// resolve binds the variables of the parsed statements and optionally optimizes them
+func (intp *Interpreter) resolve(statements []statement.Stmt) ([]statement.Stmt, map[scanner.Token]int, bool) {
+	rslvr := resolver.NewResolver()
+	rslvr.Resolve(statements)
+	if rslvr.HadError {
+		for _, err := range rslvr.Errors {
+			intp.report(err)
+		}
+		if !intp.IgnoreErrors {
+			os.Exit(statusCodes.EXIT_DATA_ERROR)
+		}
+		return nil, nil, false
+	}
+	if intp.Optimize {
+		statements = optimizer.Optimize(statements)
+	}
+	return statements, rslvr.Locals, true
+}
+
+// RunReader runs the script while it is read, one declaration after the other, so it never has to be
+// in memory as a whole. Each declaration is executed, as soon as it is parsed. The errors are reported
+// without an excerpt of the source.
+func (intp *Interpreter) RunReader(name string, reader io.Reader) {
+	intp.file, intp.source = name, ""
+	prs := parser.NewStreamParser(scanner.NewStreamScanner(reader))
+	reported := 0
+	for {
+		stmt, more := prs.ParseDeclaration()
+		if reported < len(prs.Errors()) {
+			for _, err := range prs.Errors()[reported:] {
+				intp.report(err)
+			}
+			reported = len(prs.Errors())
+			if !intp.IgnoreErrors {
+				os.Exit(statusCodes.EXIT_DATA_ERROR)
+			}
+			continue
+		}
+		if !more {
+			return
+		}
+
+		statements, locals, ok := intp.resolve([]statement.Stmt{stmt})
+		if !ok {
+			continue
+		}
+		err := intp.Backend.Interpret(statements, locals)
+		if err != nil {
+			intp.report(err)
+			if !intp.IgnoreErrors {
+				os.Exit(statusCodes.EXIT_RUNTIME_ERROR)
+			}
+		}
+	}
 }
 
 func (intp *Interpreter) runScanner(lines string) {
 	intp.Scnr.Scan(lines)
 	if intp.Scnr.HadError {
 		for _, err := range intp.Scnr.Errors {
-			fmt.Println(err.Error())
+			intp.report(err)
 		}
 		if !intp.IgnoreErrors {
 			os.Exit(statusCodes.EXIT_DATA_ERROR)
@@ -43,13 +164,27 @@ func (intp *Interpreter) runScanner(lines string) {
 	}
 }
 
+// report prints the error like rustc with the location in the source, colored if stdout is a terminal
+func (intp *Interpreter) report(err error) {
+	renderer := diagnostic.NewRenderer(intp.file, intp.source, diagnostic.IsTerminal(os.Stdout))
+	renderer.Render(os.Stdout, err)
+}
+
 func (intp *Interpreter) RunPrompt() {
 	intp.IgnoreErrors = true
+	intp.replMode = true
+	intp.file = "<repl>"
+	reader := bufio.NewReader(os.Stdin)
 	for {
-		reader := bufio.NewReader(os.Stdin)
 		fmt.Print(">> ")
-		text, _ := reader.ReadString('\n')
-		intp.run(text)
+		text, err := reader.ReadString('\n')
+		if strings.TrimSpace(text) != "" {
+			intp.run(text)
+		}
+		if err == io.EOF {
+			fmt.Println()
+			return
+		}
 	}
 }
 
@@ -60,13 +195,150 @@ func (intp *Interpreter) RunFiles(files ...string) {
 }
 
 func (intp *Interpreter) runFile(file string) {
+	if file == "-" {
+		intp.RunReader("<stdin>", os.Stdin)
+		return
+	}
+	if filepath.Ext(file) == BYTECODE_EXTENSION {
+		intp.runBytecodeFile(file)
+		return
+	}
+
 	data, err := ioutil.ReadFile(file)
 	if err != nil {
 		fmt.Println("HadError! Could not read file: ", file)
 	}
+	intp.file = file
 	fmt.Println("-------------------------------------------------------------------------------------------------------")
 	fmt.Println("-- Interpreting:", file)
 	fmt.Println("-------------------------------------------------------------------------------------------------------")
 
 	intp.run(string(data))
 }
+
+// bytecodeVM returns the vm to run bytecode files with. Bytecode can only be run by the vm,
+// so a tree-walking backend is bypassed by a fresh vm writing to stdout, configured by the BackendOptions.
+func (intp *Interpreter) bytecodeVM() *vm.VM {
+	if machine, ok := intp.Backend.(*vm.VM); ok {
+		return machine
+	}
+	backend, _ := NewBackend(BYTECODE_VM, os.Stdout, intp.BackendOptions)
+	return backend.(*vm.VM)
+}
+
+// runBytecodeFile executes a precompiled script on the bytecodeVM.
+func (intp *Interpreter) runBytecodeFile(file string) {
+	function, err := loadBytecode(file)
+	if err != nil {
+		fmt.Println(err.Error())
+		if !intp.IgnoreErrors {
+			os.Exit(statusCodes.EXIT_DATA_ERROR)
+		}
+		return
+	}
+	fmt.Println("-------------------------------------------------------------------------------------------------------")
+	fmt.Println("-- Running bytecode:", file)
+	fmt.Println("-------------------------------------------------------------------------------------------------------")
+
+	intp.file, intp.source = file, ""
+	err = intp.bytecodeVM().Run(function)
+	if err != nil {
+		intp.report(err)
+		if !intp.IgnoreErrors {
+			os.Exit(statusCodes.EXIT_RUNTIME_ERROR)
+		}
+	}
+}
+
+func loadBytecode(file string) (*compiler.Function, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return compiler.ReadBytecode(in)
+}
+
+// CompileFile compiles the lox file and writes the bytecode to output, to run it later without parsing.
+func (intp *Interpreter) CompileFile(file, output string) {
+	function, ok := intp.compileFile(file)
+	if !ok {
+		return
+	}
+	err := writeBytecode(output, function)
+	if err != nil {
+		fmt.Println(err.Error())
+		if !intp.IgnoreErrors {
+			os.Exit(statusCodes.EXIT_DATA_ERROR)
+		}
+	}
+}
+
+// compileFile reads, analyzes and compiles the lox file. Errors are reported like in run.
+func (intp *Interpreter) compileFile(file string) (*compiler.Function, bool) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		fmt.Println("HadError! Could not read file: ", file)
+		if !intp.IgnoreErrors {
+			os.Exit(statusCodes.EXIT_DATA_ERROR)
+		}
+		return nil, false
+	}
+
+	intp.file = file
+	statements, _, ok := intp.analyze(string(data))
+	if !ok {
+		return nil, false
+	}
+	function, err := compiler.Compile(statements)
+	if err != nil {
+		intp.report(err)
+		if !intp.IgnoreErrors {
+			os.Exit(statusCodes.EXIT_DATA_ERROR)
+		}
+		return nil, false
+	}
+	return function, true
+}
+
+func writeBytecode(file string, function *compiler.Function) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	err = compiler.WriteBytecode(out, function)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// DisassembleFiles compiles the files to bytecode and prints the instructions instead of running them.
+func (intp *Interpreter) DisassembleFiles(files ...string) {
+	for _, item := range files {
+		intp.disassembleFile(item)
+	}
+}
+
+func (intp *Interpreter) disassembleFile(file string) {
+	var function *compiler.Function
+	if filepath.Ext(file) == BYTECODE_EXTENSION {
+		var err error
+		function, err = loadBytecode(file)
+		if err != nil {
+			fmt.Println(err.Error())
+			if !intp.IgnoreErrors {
+				os.Exit(statusCodes.EXIT_DATA_ERROR)
+			}
+			return
+		}
+	} else {
+		var ok bool
+		function, ok = intp.compileFile(file)
+		if !ok {
+			return
+		}
+	}
+	fmt.Println("-- Disassembling:", file)
+	compiler.Disassemble(os.Stdout, function)
+}
@@ -0,0 +1,169 @@
+package interpreter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/th-lange/glox/vm"
+)
+
+func TestInterpreter_RunFiles(t *testing.T) {
+	out := bytes.Buffer{}
+	intp := Init(0)
+	intp.Backend = NewEvaluator(&out)
+
+	intp.RunFiles("../resources/statements.lox")
+	assert.Equal(t, "one\n2\ntrue\n", out.String(), "Expecting all statements of the file to be executed.")
+}
+
+func TestInterpreter_run_KeepsStateInReplMode(t *testing.T) {
+	out := bytes.Buffer{}
+	intp := Init(0)
+	intp.Backend = NewEvaluator(&out)
+	intp.IgnoreErrors = true
+	intp.replMode = true
+
+	intp.run("var counter = 1;\n")
+	intp.run("counter = counter + 1;\n")
+	intp.run("print undefined;\n")
+	intp.run("counter\n")
+	assert.Equal(t, "2\n", out.String(), "Expecting variables to survive between repl lines and runtime errors.")
+}
+
+func TestInterpreter_RegisterNative(t *testing.T) {
+	out := bytes.Buffer{}
+	intp := Init(0)
+	intp.Backend = NewEvaluator(&out)
+	intp.IgnoreErrors = true
+
+	intp.RegisterNative("double", 1, func(arguments []interface{}) (interface{}, error) {
+		number, ok := arguments[0].(float64)
+		if !ok {
+			return nil, errors.New("double expects a number.")
+		}
+		return number * 2, nil
+	})
+
+	intp.run("print double(21);")
+	assert.Equal(t, "42\n", out.String(), "Expecting the native function to be callable from lox.")
+
+	err := intp.Backend.Interpret(parseProgram(t, "double(\"text\");"))
+	assert.EqualError(t, err, "[Line 1] RuntimeError at ')' (Column 14): double expects a number.", "Expecting errors of natives to be reported at the call.")
+}
+
+func TestInterpreter_RunReader(t *testing.T) {
+	source := "var total = 0;\nfor (var i = 1; i <= 100; i = i + 1) {\n  total = total + i;\n}\nprint total;\nprint \"done\";\n"
+	for _, tb := range backends {
+		out := bytes.Buffer{}
+		intp := Init(0)
+		intp.Backend = tb.create(t, &out)
+
+		intp.RunReader("<stdin>", iotest.OneByteReader(strings.NewReader(source)))
+		assert.Equal(t, "5050\ndone\n", out.String(), "Expecting the script to be run while reading: "+tb.name)
+	}
+}
+
+func TestInterpreter_RunReader_Errors(t *testing.T) {
+	out := bytes.Buffer{}
+	intp := Init(0)
+	intp.Backend = NewEvaluator(&out)
+	intp.IgnoreErrors = true
+
+	intp.RunReader("<stdin>", strings.NewReader("print 1;\nprint ;\nprint 3;\nprint nil + 1;\nprint 5;"))
+	assert.Equal(t, "1\n3\n5\n", out.String(), "Expecting the erroneous declarations to be skipped.")
+}
+
+// generateStream writes the declarations to a pipe, so the script never exists as a whole.
+// Every declaration replaces the global function of the previous one, only the total stays reachable.
+func generateStream(declarations int) io.Reader {
+	reader, writer := io.Pipe()
+	go func() {
+		fmt.Fprintln(writer, "var total = 0;")
+		for i := 0; i < declarations; i++ {
+			fmt.Fprintf(writer, "fun step() { return \"step\" + \"%d\"; }\ntotal = total + 1;\n", i)
+		}
+		fmt.Fprintln(writer, "print total;")
+		writer.Close()
+	}()
+	return reader
+}
+
+func heapInUse() uint64 {
+	runtime.GC()
+	stats := runtime.MemStats{}
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
+
+func TestInterpreter_RunReader_LargeStreamVM(t *testing.T) {
+	out := bytes.Buffer{}
+	intp := Init(0)
+	backend, err := NewBackend(BYTECODE_VM, &out, BackendOptions{})
+	require.NoError(t, err)
+	intp.Backend = backend
+
+	intp.RunReader("<stdin>", generateStream(5000))
+	before := heapInUse()
+	intp.RunReader("<stdin>", generateStream(50000))
+	after := heapInUse()
+	// the vm has to stay in use, until the memory is measured
+	intp.RunReader("<stdin>", strings.NewReader("print total;"))
+
+	assert.Equal(t, "5000\n50000\n50000\n", out.String(), "Expecting every declaration of the streams to be run.")
+	assert.Less(t, int64(after)-int64(before), int64(4<<20), "Expecting the memory to stay bounded by the reachable objects, not by the length of the stream.")
+}
+
+func TestInterpreter_RunFiles_BytecodeVM(t *testing.T) {
+	out := bytes.Buffer{}
+	intp := Init(0)
+	backend, err := NewBackend(BYTECODE_VM, &out, BackendOptions{})
+	require.NoError(t, err)
+	intp.Backend = backend
+
+	intp.RunFiles("../resources/statements.lox")
+	assert.Equal(t, "one\n2\ntrue\n", out.String(), "Expecting the vm to execute all statements of the file.")
+}
+
+func TestInterpreter_CompileFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glox")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "statements"+BYTECODE_EXTENSION)
+	intp := Init(0)
+	intp.CompileFile("../resources/statements.lox", output)
+
+	out := bytes.Buffer{}
+	backend, err := NewBackend(BYTECODE_VM, &out, BackendOptions{})
+	require.NoError(t, err)
+	intp.Backend = backend
+	intp.RunFiles(output)
+	assert.Equal(t, "one\n2\ntrue\n", out.String(), "Expecting the precompiled file to behave like the source.")
+}
+
+func TestInterpreter_bytecodeVM(t *testing.T) {
+	out := bytes.Buffer{}
+	intp := Init(0)
+	intp.BackendOptions = BackendOptions{Debug: vm.DEBUG_TRACE, GCStress: true}
+
+	backend, err := NewBackend(BYTECODE_VM, &out, BackendOptions{})
+	require.NoError(t, err)
+	intp.Backend = backend
+	assert.Same(t, intp.Backend, intp.bytecodeVM(), "Expecting a vm backend to run the bytecode itself.")
+
+	intp.Backend = NewEvaluator(&out)
+	machine := intp.bytecodeVM()
+	assert.Equal(t, vm.DEBUG_TRACE, machine.Debug, "Expecting the fresh vm to use the debug level of the command line.")
+	assert.True(t, machine.GCStress, "Expecting the fresh vm to use the gc stress option of the command line.")
+}
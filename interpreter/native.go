@@ -0,0 +1,47 @@
+package interpreter
+
+import (
+	"fmt"
+	"time"
+)
+
+// NativeFunction makes a go function callable from lox code.
+// Errors returned by the function and results without a lox equivalent are reported as RuntimeError at the call site.
+type NativeFunction struct {
+	name     string
+	arity    int
+	function func(arguments []interface{}) (interface{}, error)
+}
+
+func NewNativeFunction(name string, arity int, function func(arguments []interface{}) (interface{}, error)) *NativeFunction {
+	return &NativeFunction{
+		name:     name,
+		arity:    arity,
+		function: function,
+	}
+}
+
+func (native *NativeFunction) Arity() int {
+	return native.arity
+}
+
+func (native *NativeFunction) Call(evaluator *Evaluator, arguments []interface{}) (interface{}, error) {
+	result, err := native.function(arguments)
+	if err != nil {
+		return nil, err
+	}
+	switch result.(type) {
+	case nil, bool, float64, string, Callable, *LoxInstance:
+		return result, nil
+	}
+	return nil, fmt.Errorf("Native returned unsupported type %T.", result)
+}
+
+func (native *NativeFunction) String() string {
+	return "<native fn " + native.name + ">"
+}
+
+// clock returns the seconds since the unix epoch
+func clock(arguments []interface{}) (interface{}, error) {
+	return float64(time.Now().UnixNano()) / float64(time.Second), nil
+}
@@ -0,0 +1,28 @@
+package interpreter
+
+import (
+	"strconv"
+
+	"github.com/th-lange/glox/diagnostic"
+	"github.com/th-lange/glox/scanner"
+)
+
+// Indicates that the EXECUTED code is erroneous, e.g. operands of the wrong type
+type RuntimeError struct {
+	Token   scanner.Token
+	Message string
+}
+
+func (re RuntimeError) Error() string {
+	return "[Line " + strconv.Itoa(re.Token.Line) + "] RuntimeError at '" + re.Token.Lexeme + "' (Column " + strconv.Itoa(re.Token.Column) + "): " + re.Message
+}
+
+func (re RuntimeError) Diagnostic() diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{
+		Code:    diagnostic.RUNTIME_ERROR,
+		Message: re.Message,
+		Line:    re.Token.Line,
+		Offset:  re.Token.Position,
+		Length:  re.Token.Length,
+	}
+}
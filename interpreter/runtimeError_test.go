@@ -0,0 +1,17 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/scanner"
+)
+
+func TestRuntimeError_Error(t *testing.T) {
+	err := RuntimeError{
+		Token:   scanner.Token{Line: 3, Column: 9, Position: 17, Type: scanner.MINUS, Lexeme: "-"},
+		Message: "Operand must be a number.",
+	}
+	assert.Equal(t, "[Line 3] RuntimeError at '-' (Column 9): Operand must be a number.", err.Error())
+}
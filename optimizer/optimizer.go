@@ -0,0 +1,333 @@
+package optimizer
+
+import (
+	"strconv"
+
+	"github.com/th-lange/glox/expression"
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+)
+
+// Optimizer rewrites the syntax tree before it is executed. Operations on literals are folded into a
+// single literal, branches which can't be taken and statements after a return are removed.
+// Operations which would fail at runtime, like -"text", are kept, so the error is still reported.
+//
+// The pass runs after the resolver. Only literals are created, all variable tokens stay
+// untouched, so the locals of the resolver still match the optimized tree.
+type Optimizer struct{}
+
+// Optimize returns the optimized statements, the passed statements are not modified.
+func Optimize(statements []statement.Stmt) []statement.Stmt {
+	return Optimizer{}.optimizeStatements(statements)
+}
+
+// optimizeStatements optimizes a list of statements. Removed statements are dropped
+// and nothing after a statement that always returns is kept.
+func (opt Optimizer) optimizeStatements(statements []statement.Stmt) []statement.Stmt {
+	optimized := make([]statement.Stmt, 0, len(statements))
+	for _, stmt := range statements {
+		result := opt.optimizeStmt(stmt)
+		if result == nil {
+			continue
+		}
+		optimized = append(optimized, result)
+		if alwaysReturns(result) {
+			break
+		}
+	}
+	return optimized
+}
+
+// optimizeStmt returns nil for statements which have no effect
+func (opt Optimizer) optimizeStmt(stmt statement.Stmt) statement.Stmt {
+	result, _ := stmt.Accept(opt).(statement.Stmt)
+	return result
+}
+
+func (opt Optimizer) optimizeExpression(expr expression.Expression) expression.Expression {
+	if expr == nil {
+		return nil
+	}
+	return expr.Accept(opt).(expression.Expression)
+}
+
+// alwaysReturns reports, whether the statements following the statement are unreachable
+func alwaysReturns(stmt statement.Stmt) bool {
+	switch stmt := stmt.(type) {
+	case statement.Return:
+		return true
+	case statement.Block:
+		return len(stmt.Statements) > 0 && alwaysReturns(stmt.Statements[len(stmt.Statements)-1])
+	case statement.If:
+		return stmt.ElseBranch != nil && alwaysReturns(stmt.ThenBranch) && alwaysReturns(stmt.ElseBranch)
+	}
+	return false
+}
+
+func (opt Optimizer) VisitExpressionStmt(stmt statement.Expression) interface{} {
+	return statement.Expression{Expr: opt.optimizeExpression(stmt.Expr)}
+}
+
+func (opt Optimizer) VisitPrintStmt(stmt statement.Print) interface{} {
+	return statement.Print{Expr: opt.optimizeExpression(stmt.Expr)}
+}
+
+func (opt Optimizer) VisitVarStmt(stmt statement.Var) interface{} {
+	return statement.Var{Name: stmt.Name, Initializer: opt.optimizeExpression(stmt.Initializer)}
+}
+
+func (opt Optimizer) VisitBlockStmt(stmt statement.Block) interface{} {
+	return statement.Block{Statements: opt.optimizeStatements(stmt.Statements)}
+}
+
+// VisitIfStmt replaces the statement with the taken branch, if the condition is a literal
+func (opt Optimizer) VisitIfStmt(stmt statement.If) interface{} {
+	condition := opt.optimizeExpression(stmt.Condition)
+	if literal, ok := condition.(expression.Literal); ok {
+		if isTruthy(literal) {
+			return opt.optimizeStmt(stmt.ThenBranch)
+		}
+		if stmt.ElseBranch == nil {
+			return nil
+		}
+		return opt.optimizeStmt(stmt.ElseBranch)
+	}
+
+	thenBranch := opt.optimizeStmt(stmt.ThenBranch)
+	if thenBranch == nil {
+		thenBranch = statement.Block{}
+	}
+	var elseBranch statement.Stmt
+	if stmt.ElseBranch != nil {
+		elseBranch = opt.optimizeStmt(stmt.ElseBranch)
+	}
+	return statement.If{Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}
+}
+
+// VisitWhileStmt removes loops, which are never entered
+func (opt Optimizer) VisitWhileStmt(stmt statement.While) interface{} {
+	condition := opt.optimizeExpression(stmt.Condition)
+	if literal, ok := condition.(expression.Literal); ok && !isTruthy(literal) {
+		return nil
+	}
+	body := opt.optimizeStmt(stmt.Body)
+	if body == nil {
+		body = statement.Block{}
+	}
+	return statement.While{Condition: condition, Body: body}
+}
+
+func (opt Optimizer) VisitFunctionStmt(stmt statement.Function) interface{} {
+	return opt.optimizeFunction(stmt)
+}
+
+func (opt Optimizer) optimizeFunction(stmt statement.Function) statement.Function {
+	return statement.Function{Name: stmt.Name, Params: stmt.Params, Body: opt.optimizeStatements(stmt.Body)}
+}
+
+func (opt Optimizer) VisitReturnStmt(stmt statement.Return) interface{} {
+	return statement.Return{Keyword: stmt.Keyword, Value: opt.optimizeExpression(stmt.Value)}
+}
+
+func (opt Optimizer) VisitClassStmt(stmt statement.Class) interface{} {
+	methods := make([]statement.Function, len(stmt.Methods))
+	for i, method := range stmt.Methods {
+		methods[i] = opt.optimizeFunction(method)
+	}
+	return statement.Class{Name: stmt.Name, Superclass: stmt.Superclass, Methods: methods}
+}
+
+func (opt Optimizer) VisitBinary(expr expression.Binary) interface{} {
+	left := opt.optimizeExpression(expr.Left)
+	right := opt.optimizeExpression(expr.Right)
+	optimized := expression.Binary{Left: left, Operator: expr.Operator, Right: right}
+
+	l, lok := left.(expression.Literal)
+	r, rok := right.(expression.Literal)
+	if !lok || !rok {
+		return optimized
+	}
+
+	switch expr.Operator.Type {
+	case scanner.EQUAL_EQUAL:
+		return boolLiteral(expr.Operator, isEqual(l, r))
+	case scanner.BANG_EQUAL:
+		return boolLiteral(expr.Operator, !isEqual(l, r))
+	case scanner.PLUS:
+		if l.Value.Type == scanner.STRING && r.Value.Type == scanner.STRING {
+			return stringLiteral(expr.Operator, l.Value.Literal.(string)+r.Value.Literal.(string))
+		}
+	}
+
+	if l.Value.Type != scanner.NUMBER || r.Value.Type != scanner.NUMBER {
+		return optimized
+	}
+	a, b := l.Value.Literal.(float64), r.Value.Literal.(float64)
+	switch expr.Operator.Type {
+	case scanner.PLUS:
+		return numberLiteral(expr.Operator, a+b)
+	case scanner.MINUS:
+		return numberLiteral(expr.Operator, a-b)
+	case scanner.STAR:
+		return numberLiteral(expr.Operator, a*b)
+	case scanner.SLASH:
+		return numberLiteral(expr.Operator, a/b)
+	case scanner.GREATER:
+		return boolLiteral(expr.Operator, a > b)
+	case scanner.GREATER_EQUAL:
+		return boolLiteral(expr.Operator, a >= b)
+	case scanner.LESS:
+		return boolLiteral(expr.Operator, a < b)
+	case scanner.LESS_EQUAL:
+		return boolLiteral(expr.Operator, a <= b)
+	}
+	return optimized
+}
+
+func (opt Optimizer) VisitGrouping(expr expression.Grouping) interface{} {
+	inner := opt.optimizeExpression(expr.Expr)
+	if literal, ok := inner.(expression.Literal); ok {
+		return literal
+	}
+	return expression.Grouping{Expr: inner}
+}
+
+func (opt Optimizer) VisitLiteral(expr expression.Literal) interface{} {
+	return expr
+}
+
+func (opt Optimizer) VisitUnary(expr expression.Unary) interface{} {
+	right := opt.optimizeExpression(expr.Right)
+	if literal, ok := right.(expression.Literal); ok {
+		switch {
+		case expr.Operator.Type == scanner.BANG:
+			return boolLiteral(expr.Operator, !isTruthy(literal))
+		case expr.Operator.Type == scanner.MINUS && literal.Value.Type == scanner.NUMBER:
+			return numberLiteral(expr.Operator, -literal.Value.Literal.(float64))
+		}
+	}
+	return expression.Unary{Operator: expr.Operator, Right: right}
+}
+
+func (opt Optimizer) VisitVariable(expr expression.Variable) interface{} {
+	return expr
+}
+
+func (opt Optimizer) VisitAssign(expr expression.Assign) interface{} {
+	return expression.Assign{Name: expr.Name, Value: opt.optimizeExpression(expr.Value)}
+}
+
+// VisitLogical decides the operation, if the left operand is a literal
+func (opt Optimizer) VisitLogical(expr expression.Logical) interface{} {
+	left := opt.optimizeExpression(expr.Left)
+	right := opt.optimizeExpression(expr.Right)
+	if literal, ok := left.(expression.Literal); ok {
+		if isTruthy(literal) == (expr.Operator.Type == scanner.OR) {
+			return literal
+		}
+		return right
+	}
+	return expression.Logical{Left: left, Operator: expr.Operator, Right: right}
+}
+
+func (opt Optimizer) VisitCall(expr expression.Call) interface{} {
+	arguments := make([]expression.Expression, len(expr.Arguments))
+	for i, argument := range expr.Arguments {
+		arguments[i] = opt.optimizeExpression(argument)
+	}
+	return expression.Call{Callee: opt.optimizeExpression(expr.Callee), Paren: expr.Paren, Arguments: arguments}
+}
+
+func (opt Optimizer) VisitGet(expr expression.Get) interface{} {
+	return expression.Get{Object: opt.optimizeExpression(expr.Object), Name: expr.Name}
+}
+
+func (opt Optimizer) VisitSet(expr expression.Set) interface{} {
+	return expression.Set{Object: opt.optimizeExpression(expr.Object), Name: expr.Name, Value: opt.optimizeExpression(expr.Value)}
+}
+
+func (opt Optimizer) VisitThis(expr expression.This) interface{} {
+	return expr
+}
+
+func (opt Optimizer) VisitSuper(expr expression.Super) interface{} {
+	return expr
+}
+
+// VisitInterpolation folds the string, if every embedded expression is a literal
+func (opt Optimizer) VisitInterpolation(expr expression.Interpolation) interface{} {
+	parts := make([]expression.Expression, len(expr.Parts))
+	text := ""
+	folded := true
+	for i, part := range expr.Parts {
+		parts[i] = opt.optimizeExpression(part)
+		if literal, ok := parts[i].(expression.Literal); ok && folded {
+			text += stringify(literal)
+		} else {
+			folded = false
+		}
+	}
+	if folded {
+		return stringLiteral(expr.Parts[0].(expression.Literal).Value, text)
+	}
+	return expression.Interpolation{Parts: parts}
+}
+
+// nil and false are falsey, everything else is truthy
+func isTruthy(literal expression.Literal) bool {
+	return literal.Value.Type != scanner.NIL && literal.Value.Type != scanner.FALSE
+}
+
+// isEqual compares literals the way the backends compare the values at runtime
+func isEqual(left, right expression.Literal) bool {
+	if left.Value.Type != right.Value.Type {
+		return false
+	}
+	switch left.Value.Type {
+	case scanner.NUMBER:
+		return left.Value.Literal.(float64) == right.Value.Literal.(float64)
+	case scanner.STRING:
+		return left.Value.Literal.(string) == right.Value.Literal.(string)
+	}
+	return true
+}
+
+// stringify converts the literal like the backends convert embedded values
+func stringify(literal expression.Literal) string {
+	switch literal.Value.Type {
+	case scanner.NIL, scanner.TRUE, scanner.FALSE:
+		return literal.Value.Lexeme
+	case scanner.NUMBER:
+		return strconv.FormatFloat(literal.Value.Literal.(float64), 'f', -1, 64)
+	}
+	return literal.Value.Literal.(string)
+}
+
+// The folded literals take the position of the operator, so errors still point to the original expression.
+
+func numberLiteral(operator scanner.Token, value float64) expression.Literal {
+	return literal(operator, scanner.NUMBER, strconv.FormatFloat(value, 'f', -1, 64), value)
+}
+
+func stringLiteral(operator scanner.Token, value string) expression.Literal {
+	return literal(operator, scanner.STRING, value, value)
+}
+
+func boolLiteral(operator scanner.Token, value bool) expression.Literal {
+	if value {
+		return literal(operator, scanner.TRUE, "true", nil)
+	}
+	return literal(operator, scanner.FALSE, "false", nil)
+}
+
+func literal(operator scanner.Token, tokenType scanner.TokenType, lexeme string, value interface{}) expression.Literal {
+	return expression.Literal{Value: scanner.Token{
+		Type:     tokenType,
+		Lexeme:   lexeme,
+		Literal:  value,
+		Line:     operator.Line,
+		Column:   operator.Column,
+		Position: operator.Position,
+		Length:   operator.Length,
+	}}
+}
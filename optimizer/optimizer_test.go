@@ -0,0 +1,123 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/expression"
+	"github.com/th-lange/glox/parser"
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+	"github.com/th-lange/glox/visitor"
+)
+
+func optimizeSource(t *testing.T, source string) []statement.Stmt {
+	scnr := scanner.Scanner{}
+	scnr.Scan(source)
+	assert.False(t, scnr.HadError, "Expecting the source to be scanned without errors: "+source)
+	statements := parser.NewParser(&scnr.Tokens).Parse()
+	assert.NotNil(t, statements, "Expecting the source to be parsed without errors: "+source)
+	return Optimize(statements)
+}
+
+func optimizeExpression(t *testing.T, source string) expression.Expression {
+	statements := optimizeSource(t, source+";")
+	return statements[0].(statement.Expression).Expr
+}
+
+func TestOptimizer_FoldsConstants(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected interface{}
+		lexeme   string
+	}{
+		{"(1 + 2) * 3", 9.0, "9"},
+		{"10 / 4 - -1", 3.5, "3.5"},
+		{`"a" + ("b" + "c")`, "abc", "abc"},
+		{"1 < 2", nil, "true"},
+		{"!nil", nil, "true"},
+		{`"a" == "a"`, nil, "true"},
+		{"1 == true", nil, "false"},
+		{"nil == nil", nil, "true"},
+		{"false or 2", 2.0, "2"},
+		{"nil and 2", nil, "nil"},
+		{`"a ${1 + 2} ${nil}${!nil}"`, "a 3 niltrue", "a 3 niltrue"},
+	}
+
+	for _, tt := range tests {
+		literal, ok := optimizeExpression(t, tt.source).(expression.Literal)
+		assert.True(t, ok, "Expecting the expression to be folded: "+tt.source)
+		assert.Equal(t, tt.expected, literal.Value.Literal, tt.source)
+		assert.Equal(t, tt.lexeme, literal.Value.Lexeme, tt.source)
+	}
+}
+
+func TestOptimizer_KeepsNonConstantExpressions(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"a + (1 + 2)", " ( + a  3  ) "},
+		{"-(1 + 2) * b", " ( * -3  b  ) "},
+		{`-"text"`, " ( - text  ) "},
+		{`1 + "text"`, " ( + 1  text  ) "},
+		{"a or 1 + 1", " ( or a  2  ) "},
+		{`"a ${b} ${1 + 2}"`, " ( interpolation a   b     3    ) "},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, optimizeExpression(t, tt.source).Accept(visitor.PrettyPrinter{}), tt.source)
+	}
+}
+
+func TestOptimizer_FoldedLiteralKeepsPosition(t *testing.T) {
+	literal := optimizeExpression(t, "\n1 +\n2").(expression.Literal)
+	assert.Equal(t, 2, literal.Value.Line, "Expecting the literal to be reported at the operator.")
+}
+
+func TestOptimizer_RemovesDeadBranches(t *testing.T) {
+	statements := optimizeSource(t, `if (false) print 1;
+if (1 > 2) print 2; else print 3;
+if (true) print 4; else print 5;
+while (false) print 6;
+if (a) print 7;`)
+
+	assert.Len(t, statements, 3)
+	assert.Equal(t, 3.0, statements[0].(statement.Print).Expr.(expression.Literal).Value.Literal)
+	assert.Equal(t, 4.0, statements[1].(statement.Print).Expr.(expression.Literal).Value.Literal)
+	assert.IsType(t, statement.If{}, statements[2])
+}
+
+func TestOptimizer_RemovesCodeAfterReturn(t *testing.T) {
+	statements := optimizeSource(t, `fun f(a) {
+  print 1;
+  return 2;
+  print 3;
+}
+fun g(a) {
+  if (a) return 1; else { return 2; }
+  print 3;
+}
+fun h(a) {
+  if (a) return 1;
+  print 2;
+}`)
+
+	assert.Len(t, statements[0].(statement.Function).Body, 2)
+	assert.Len(t, statements[1].(statement.Function).Body, 1, "Expecting the code after an if, which returns in both branches, to be removed.")
+	assert.Len(t, statements[2].(statement.Function).Body, 2, "Expecting the code after a conditional return to be kept.")
+}
+
+func TestOptimizer_OptimizesMethods(t *testing.T) {
+	statements := optimizeSource(t, `class A {
+  m() {
+    return 1 + 1;
+    print "unreachable";
+  }
+}`)
+
+	method := statements[0].(statement.Class).Methods[0]
+	assert.Len(t, method.Body, 1)
+	assert.Equal(t, 2.0, method.Body[0].(statement.Return).Value.(expression.Literal).Value.Literal)
+}
@@ -2,16 +2,31 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/th-lange/glox/expression"
 	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
 )
 
+// maxArguments limits the arguments of a call and the parameters of a function
+const maxArguments = 255
+
+// TokenSource yields the tokens one at a time, the last one is EOF. Errors don't end the tokens,
+// the next call continues after them. The stream scanner is a TokenSource.
+type TokenSource interface {
+	Next() (scanner.Token, error)
+}
+
+// The tokens of a stream parser are read from source when the head reaches them. The tokens of
+// parsed declarations are dropped, so only the current declaration is kept in memory.
 type parser struct {
-	tokens *[]scanner.Token
-	last   int
-	head   int
-	errors []error
+	tokens   *[]scanner.Token
+	source   TokenSource
+	last     int
+	head     int
+	errors   []error
+	replMode bool
 }
 
 func NewParser(tokens *[]scanner.Token) *parser {
@@ -25,6 +40,14 @@ func NewParser(tokens *[]scanner.Token) *parser {
 	return prs
 }
 
+// NewStreamParser parses the tokens of source as they are needed. The errors of the source are
+// recorded with the parsing errors.
+func NewStreamParser(source TokenSource) *parser {
+	prs := NewParser(&[]scanner.Token{})
+	prs.source = source
+	return prs
+}
+
 func (prs parser) String() string {
 	if prs.last > 0 {
 		return fmt.Sprintf("Parser: Head: %d, Last: %d, Current Token: %s", prs.head, prs.last, (*prs.tokens)[prs.head].String())
@@ -33,22 +56,303 @@ func (prs parser) String() string {
 
 }
 
-func (prs parser) Parse() expression.Expression {
+// EnableReplMode allows the last statement to be a bare expression without a trailing semicolon.
+// Its value will be printed, just like a print statement.
+func (prs *parser) EnableReplMode() {
+	prs.replMode = true
+}
+
+// Errors returns all errors found by Parse in the order of the source, for a stream parser including
+// the errors of its source.
+func (prs *parser) Errors() []error {
+	return prs.errors
+}
+
+// program        → declaration* EOF ;
+// Parse returns nil, if the program contains errors. The parsing continues after an error,
+// so all errors are reported at once.
+func (prs *parser) Parse() []statement.Stmt {
+	statements := make([]statement.Stmt, 0, 8)
+	for {
+		stmt, ok := prs.ParseDeclaration()
+		if !ok {
+			break
+		}
+		if stmt != nil {
+			statements = append(statements, stmt)
+		}
+	}
+	if len(prs.errors) > 0 {
+		return nil
+	}
+	return statements
+}
+
+// ParseDeclaration parses the next declaration only, to run a stream of tokens one declaration at a time.
+// It is false at the end of the tokens. The statement is nil, if the declaration is erroneous, see Errors.
+func (prs *parser) ParseDeclaration() (statement.Stmt, bool) {
+	prs.discard()
+	if prs.isAtEnd() || prs.check(scanner.EOF) {
+		return nil, false
+	}
+	return prs.declaration(), true
+}
+
+// declaration    → classDecl | funDecl | varDecl | statement ;
+// A ParsingError aborts the declaration. It is recorded and the parser skips to the next statement, nil is returned instead.
+func (prs *parser) declaration() (stmt statement.Stmt) {
 	defer func() {
-		r := recover()
-		switch r.(type) {
-		case InvalidArgumentError:
-			panic(r)
-		case ParsingError:
-			fmt.Print("Found error: ", r.(ParsingError).Error())
+		if r := recover(); r != nil {
+			err, ok := r.(ParsingError)
+			if !ok {
+				panic(r)
+			}
+			prs.report(err)
+			prs.synchronize()
+			stmt = nil
 		}
 	}()
-	return prs.expression()
+
+	if prs.advanceOnTokenTypeMatch(scanner.CLASS) {
+		return prs.classDeclaration()
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.FUN) {
+		return prs.function()
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.VAR) {
+		return prs.varDeclaration()
+	}
+	return prs.statement()
+}
+
+// classDecl      → "class" IDENTIFIER ( "<" IDENTIFIER )? "{" function* "}" ;
+func (prs *parser) classDeclaration() statement.Stmt {
+	name := prs.require(scanner.IDENTIFIER)
+
+	var superclass *expression.Variable
+	if prs.advanceOnTokenTypeMatch(scanner.LESS) {
+		superclass = &expression.Variable{Name: prs.require(scanner.IDENTIFIER)}
+		if superclass.Name.Lexeme == name.Lexeme {
+			prs.report(NewError(superclass.Name, "A class can't inherit from itself."))
+		}
+	}
+	prs.require(scanner.LEFT_BRACE)
+
+	methods := make([]statement.Function, 0, 4)
+	for !prs.check(scanner.RIGHT_BRACE) && !prs.check(scanner.EOF) && !prs.isAtEnd() {
+		methods = append(methods, prs.function())
+	}
+	prs.require(scanner.RIGHT_BRACE)
+	return statement.Class{Name: name, Superclass: superclass, Methods: methods}
+}
+
+// funDecl        → "fun" function ;
+// function       → IDENTIFIER "(" parameters? ")" block ;
+// parameters     → IDENTIFIER ( "," IDENTIFIER )* ;
+func (prs *parser) function() statement.Function {
+	name := prs.require(scanner.IDENTIFIER)
+	prs.require(scanner.LEFT_PAREN)
+	parameters := make([]scanner.Token, 0, 4)
+	if !prs.check(scanner.RIGHT_PAREN) {
+		for {
+			if len(parameters) == maxArguments {
+				prs.report(NewError(prs.current(), "Can't have more than "+strconv.Itoa(maxArguments)+" parameters."))
+			}
+			parameters = append(parameters, prs.require(scanner.IDENTIFIER))
+			if !prs.advanceOnTokenTypeMatch(scanner.COMMA) {
+				break
+			}
+		}
+	}
+	prs.require(scanner.RIGHT_PAREN)
+
+	prs.require(scanner.LEFT_BRACE)
+	body := prs.block()
+	return statement.Function{Name: name, Params: parameters, Body: body}
+}
+
+// varDecl        → "var" IDENTIFIER ( "=" expression )? ";" ;
+func (prs *parser) varDeclaration() statement.Stmt {
+	name := prs.require(scanner.IDENTIFIER)
+
+	var initializer expression.Expression
+	if prs.advanceOnTokenTypeMatch(scanner.EQUAL) {
+		initializer = prs.expression()
+	}
+	prs.require(scanner.SEMICOLON)
+	return statement.Var{Name: name, Initializer: initializer}
+}
+
+// statement      → exprStmt | forStmt | ifStmt | printStmt | returnStmt | whileStmt | block ;
+func (prs *parser) statement() statement.Stmt {
+	if prs.advanceOnTokenTypeMatch(scanner.FOR) {
+		return prs.forStatement()
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.IF) {
+		return prs.ifStatement()
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.PRINT) {
+		return prs.printStatement()
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.RETURN) {
+		return prs.returnStatement()
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.WHILE) {
+		return prs.whileStatement()
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.LEFT_BRACE) {
+		return statement.Block{Statements: prs.block()}
+	}
+	return prs.expressionStatement()
+}
+
+// block          → "{" declaration* "}" ;
+func (prs *parser) block() []statement.Stmt {
+	statements := make([]statement.Stmt, 0, 8)
+	for !prs.check(scanner.RIGHT_BRACE) && !prs.check(scanner.EOF) && !prs.isAtEnd() {
+		if stmt := prs.declaration(); stmt != nil {
+			statements = append(statements, stmt)
+		}
+	}
+	prs.require(scanner.RIGHT_BRACE)
+	return statements
 }
 
-// expression     → equality ;
+// forStmt        → "for" "(" ( varDecl | exprStmt | ";" ) expression? ";" expression? ")" statement ;
+// The for loop is desugared into a while loop:
+// { initializer; while (condition) { body; increment; } }
+func (prs *parser) forStatement() statement.Stmt {
+	prs.require(scanner.LEFT_PAREN)
+
+	var initializer statement.Stmt
+	if prs.advanceOnTokenTypeMatch(scanner.VAR) {
+		initializer = prs.varDeclaration()
+	} else if !prs.advanceOnTokenTypeMatch(scanner.SEMICOLON) {
+		initializer = prs.expressionStatement()
+	}
+
+	var condition expression.Expression
+	if !prs.check(scanner.SEMICOLON) {
+		condition = prs.expression()
+	}
+	semicolon := prs.require(scanner.SEMICOLON)
+
+	var increment expression.Expression
+	if !prs.check(scanner.RIGHT_PAREN) {
+		increment = prs.expression()
+	}
+	prs.require(scanner.RIGHT_PAREN)
+
+	body := prs.statement()
+
+	if increment != nil {
+		body = statement.Block{Statements: []statement.Stmt{body, statement.Expression{Expr: increment}}}
+	}
+	if condition == nil {
+		condition = expression.Literal{Value: scanner.Token{Type: scanner.TRUE, Lexeme: "true", Line: semicolon.Line, Column: semicolon.Column, Position: semicolon.Position}}
+	}
+	body = statement.While{Condition: condition, Body: body}
+	if initializer != nil {
+		body = statement.Block{Statements: []statement.Stmt{initializer, body}}
+	}
+	return body
+}
+
+// ifStmt         → "if" "(" expression ")" statement ( "else" statement )? ;
+func (prs *parser) ifStatement() statement.Stmt {
+	prs.require(scanner.LEFT_PAREN)
+	condition := prs.expression()
+	prs.require(scanner.RIGHT_PAREN)
+
+	thenBranch := prs.statement()
+	var elseBranch statement.Stmt
+	if prs.advanceOnTokenTypeMatch(scanner.ELSE) {
+		elseBranch = prs.statement()
+	}
+	return statement.If{Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}
+}
+
+// returnStmt     → "return" expression? ";" ;
+func (prs *parser) returnStatement() statement.Stmt {
+	keyword := prs.previous()
+	var value expression.Expression
+	if !prs.check(scanner.SEMICOLON) {
+		value = prs.expression()
+	}
+	prs.require(scanner.SEMICOLON)
+	return statement.Return{Keyword: keyword, Value: value}
+}
+
+// whileStmt      → "while" "(" expression ")" statement ;
+func (prs *parser) whileStatement() statement.Stmt {
+	prs.require(scanner.LEFT_PAREN)
+	condition := prs.expression()
+	prs.require(scanner.RIGHT_PAREN)
+	body := prs.statement()
+	return statement.While{Condition: condition, Body: body}
+}
+
+// printStmt      → "print" expression ";" ;
+func (prs *parser) printStatement() statement.Stmt {
+	value := prs.expression()
+	prs.require(scanner.SEMICOLON)
+	return statement.Print{Expr: value}
+}
+
+// exprStmt       → expression ";" ;
+func (prs *parser) expressionStatement() statement.Stmt {
+	expr := prs.expression()
+	if prs.replMode && prs.check(scanner.EOF) {
+		return statement.Print{Expr: expr}
+	}
+	prs.require(scanner.SEMICOLON)
+	return statement.Expression{Expr: expr}
+}
+
+// expression     → assignment ;
 func (prs *parser) expression() expression.Expression {
-	return prs.equality()
+	return prs.assignment()
+}
+
+// assignment     → ( call "." )? IDENTIFIER "=" assignment | logic_or ;
+func (prs *parser) assignment() expression.Expression {
+	expr := prs.or()
+
+	if prs.advanceOnTokenTypeMatch(scanner.EQUAL) {
+		equals := prs.previous()
+		value := prs.assignment()
+		switch target := expr.(type) {
+		case expression.Variable:
+			return expression.Assign{Name: target.Name, Value: value}
+		case expression.Get:
+			return expression.Set{Object: target.Object, Name: target.Name, Value: value}
+		}
+		// the parser isn't confused by the target, so it continues without synchronizing
+		prs.report(ParsingError{Kind: INVALID_ASSIGNMENT_TARGET, Token: equals, Message: "Invalid assignment target."})
+	}
+	return expr
+}
+
+// logic_or       → logic_and ( "or" logic_and )* ;
+func (prs *parser) or() expression.Expression {
+	expr := prs.and()
+	for prs.advanceOnTokenTypeMatch(scanner.OR) {
+		operator := prs.previous()
+		right := prs.and()
+		expr = expression.Logical{Left: expr, Operator: operator, Right: right}
+	}
+	return expr
+}
+
+// logic_and      → equality ( "and" equality )* ;
+func (prs *parser) and() expression.Expression {
+	expr := prs.equality()
+	for prs.advanceOnTokenTypeMatch(scanner.AND) {
+		operator := prs.previous()
+		right := prs.equality()
+		expr = expression.Logical{Left: expr, Operator: operator, Right: right}
+	}
+	return expr
 }
 
 // equality       → comparison ( ( "!=" | "==" ) comparison )* ;
@@ -95,31 +399,89 @@ func (prs *parser) multiplication() expression.Expression {
 	return expr
 }
 
-// unary          → ( "!" | "-" ) unary    |    primary ;
+// unary          → ( "!" | "-" ) unary    |    call ;
 func (prs *parser) unary() expression.Expression {
 	if prs.advanceOnTokenTypeMatch(scanner.BANG, scanner.MINUS) {
 		operator := prs.previous()
 		right := prs.unary()
 		return expression.Unary{operator, right}
 	}
-	return prs.primary()
+	return prs.call()
+}
+
+// call           → primary ( "(" arguments? ")" | "." IDENTIFIER )* ;
+func (prs *parser) call() expression.Expression {
+	expr := prs.primary()
+	for {
+		if prs.advanceOnTokenTypeMatch(scanner.LEFT_PAREN) {
+			expr = prs.finishCall(expr)
+		} else if prs.advanceOnTokenTypeMatch(scanner.DOT) {
+			name := prs.require(scanner.IDENTIFIER)
+			expr = expression.Get{Object: expr, Name: name}
+		} else {
+			return expr
+		}
+	}
 }
 
-//primary        → NUMBER | STRING | "false" | "true" | "nil"   |    "(" expression ")" ;
+// arguments      → expression ( "," expression )* ;
+func (prs *parser) finishCall(callee expression.Expression) expression.Expression {
+	arguments := make([]expression.Expression, 0, 4)
+	if !prs.check(scanner.RIGHT_PAREN) {
+		for {
+			if len(arguments) == maxArguments {
+				prs.report(NewError(prs.current(), "Can't have more than "+strconv.Itoa(maxArguments)+" arguments."))
+			}
+			arguments = append(arguments, prs.expression())
+			if !prs.advanceOnTokenTypeMatch(scanner.COMMA) {
+				break
+			}
+		}
+	}
+	paren := prs.require(scanner.RIGHT_PAREN)
+	return expression.Call{Callee: callee, Paren: paren, Arguments: arguments}
+}
+
+// primary        → NUMBER | STRING | "false" | "true" | "nil" | "this"   |    "(" expression ")"   |    IDENTIFIER   |    "super" "." IDENTIFIER   |   interpolation ;
 func (prs *parser) primary() expression.Expression {
 	if prs.advanceOnTokenTypeMatch(scanner.FALSE, scanner.TRUE, scanner.NIL, scanner.STRING, scanner.NUMBER) {
 		return expression.Literal{prs.previous()}
 	}
+	if prs.advanceOnTokenTypeMatch(scanner.INTERPOLATION) {
+		return prs.interpolation()
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.THIS) {
+		return expression.This{Keyword: prs.previous()}
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.SUPER) {
+		keyword := prs.previous()
+		prs.require(scanner.DOT)
+		return expression.Super{Keyword: keyword, Method: prs.require(scanner.IDENTIFIER)}
+	}
+	if prs.advanceOnTokenTypeMatch(scanner.IDENTIFIER) {
+		return expression.Variable{Name: prs.previous()}
+	}
 	if prs.advanceOnTokenTypeMatch(scanner.LEFT_PAREN) {
 		expr := prs.expression()
-		err := prs.consume(scanner.RIGHT_PAREN)
-		if err != nil {
-			// We should do more here!
-			prs.errors = append(prs.errors, err)
-		}
+		prs.require(scanner.RIGHT_PAREN)
 		return expression.Grouping{expr}
 	}
-	panic(NewError("Found end of Grammar in parser.primary. Expected one of the following: FALSE, TRUE, NIL, STRING, NUMBER, LEFT_PAREN.", true, prs))
+	panic(NewError(prs.current(), "Expect expression."))
+}
+
+// interpolation  → ( INTERPOLATION expression )+ STRING ;
+// The parts alternate between the string segments and the embedded expressions: "a${b}c" has the parts "a", b and "c".
+func (prs *parser) interpolation() expression.Expression {
+	parts := []expression.Expression{expression.Literal{prs.previous()}}
+	for {
+		parts = append(parts, prs.expression())
+
+		if !prs.advanceOnTokenTypeMatch(scanner.INTERPOLATION) {
+			prs.require(scanner.STRING)
+			return expression.Interpolation{Parts: append(parts, expression.Literal{prs.previous()})}
+		}
+		parts = append(parts, expression.Literal{prs.previous()})
+	}
 }
 
 func (prs *parser) advanceOnTokenTypeMatch(tokenTypes ...scanner.TokenType) bool {
@@ -140,14 +502,43 @@ func (prs *parser) check(tokenType scanner.TokenType) bool {
 }
 
 func (prs *parser) isAtEnd() bool {
+	prs.fill()
 	return prs.head > prs.last
 }
 
+// fill reads the tokens of the source up to the head
+func (prs *parser) fill() {
+	for prs.source != nil && prs.head > prs.last {
+		tkn, err := prs.source.Next()
+		if err != nil {
+			prs.errors = append(prs.errors, err)
+			continue
+		}
+		*prs.tokens = append(*prs.tokens, tkn)
+		prs.last += 1
+		if tkn.Type == scanner.EOF {
+			prs.source = nil
+		}
+	}
+}
+
+// discard drops the tokens before the previous one of a stream parser
+func (prs *parser) discard() {
+	if prs.source == nil || prs.head < 2 {
+		return
+	}
+	kept := copy(*prs.tokens, (*prs.tokens)[prs.head-1:])
+	*prs.tokens = (*prs.tokens)[:kept]
+	prs.last = kept - 1
+	prs.head = 1
+}
+
 func (prs *parser) advance() {
 	prs.head += 1
 }
 
 func (prs *parser) current() scanner.Token {
+	prs.fill()
 	if prs.head < 0 && prs.head > prs.last {
 		panic(InvalidArgumentError{"Cound not return current element as HEAD is below 0 or above last of elements: " + prs.String()})
 	}
@@ -159,7 +550,18 @@ func (prs *parser) consume(tokenType scanner.TokenType) error {
 		prs.advance()
 		return nil
 	}
-	return NewError("Could not find expected Token: "+tokenType.String(), true, prs)
+	err := NewError(prs.current(), "Could not find expected Token: "+tokenType.String())
+	err.Kind = missingTokens[tokenType]
+	return err
+}
+
+// require consumes the expected token or aborts the parsing with the ParsingError of consume
+func (prs *parser) require(tokenType scanner.TokenType) scanner.Token {
+	err := prs.consume(tokenType)
+	if err != nil {
+		panic(err)
+	}
+	return prs.previous()
 }
 
 func (prs *parser) previous() scanner.Token {
@@ -170,15 +572,26 @@ func (prs *parser) previous() scanner.Token {
 	return (*prs.tokens)[prs.head-1]
 }
 
+// report records an error without aborting the current declaration
+func (prs *parser) report(err ParsingError) {
+	prs.errors = append(prs.errors, err)
+}
+
+// synchronize skips the tokens of the erroneous statement. It stops after a semicolon or before a
+// keyword starting a new statement. The token the error was found at is always skipped, so the parser makes progress.
 func (prs *parser) synchronize() {
-	fmt.Println("In Synchronize:" + prs.String())
-	for !prs.isAtEnd() {
-		fmt.Println("In Synchronize:" + prs.String())
-		prs.advance()
-		// THIS IS: HEAD-1 ... but why?!
-		switch (*prs.tokens)[prs.head-1].Type {
+	if prs.isAtEnd() || prs.check(scanner.EOF) {
+		return
+	}
+	prs.advance()
+	for !prs.isAtEnd() && !prs.check(scanner.EOF) {
+		if prs.previous().Type == scanner.SEMICOLON {
+			return
+		}
+		switch prs.current().Type {
 		case scanner.CLASS, scanner.FUN, scanner.VAR, scanner.FOR, scanner.IF, scanner.WHILE, scanner.PRINT, scanner.RETURN:
 			return
 		}
+		prs.advance()
 	}
 }
@@ -3,33 +3,63 @@ package parser
 import (
 	"strconv"
 
+	"github.com/th-lange/glox/diagnostic"
 	"github.com/th-lange/glox/scanner"
 )
 
+// ErrorKind identifies a ParsingError independent of its message, errors without a hint keep the zero kind
+type ErrorKind int
+
+const (
+	INVALID_SYNTAX ErrorKind = iota
+	MISSING_SEMICOLON
+	MISSING_RIGHT_PAREN
+	MISSING_RIGHT_BRACE
+	INVALID_ASSIGNMENT_TARGET
+)
+
 // Indicates that the PARSED code is erroneous
 type ParsingError struct {
-	ErrorStart    scanner.Token
-	TokenPosition int
-	Message       string
-	SyncEnd       *scanner.Token
+	Kind    ErrorKind
+	Token   scanner.Token
+	Message string
 }
 
 func (pe ParsingError) Error() string {
-	endMsg := ""
-	if pe.SyncEnd != nil {
-		endMsg = "\nSynced Up to Element: " + pe.SyncEnd.String()
+	location := "'" + pe.Token.Lexeme + "'"
+	if pe.Token.Type == scanner.EOF {
+		location = "end"
 	}
-	return "[Token Position " + strconv.Itoa(pe.TokenPosition) + "] Last Valid Element" + (pe.ErrorStart).String() + "!\n" + pe.Message + endMsg
+	return "[Line " + strconv.Itoa(pe.Token.Line) + "] ParsingError at " + location + " (Column " + strconv.Itoa(pe.Token.Column) + "): " + pe.Message
+}
+
+func NewError(token scanner.Token, message string) ParsingError {
+	return ParsingError{Token: token, Message: message}
+}
+
+// missingTokens are the kinds of the errors reported, when an expected token is missing
+var missingTokens = map[scanner.TokenType]ErrorKind{
+	scanner.SEMICOLON:   MISSING_SEMICOLON,
+	scanner.RIGHT_PAREN: MISSING_RIGHT_PAREN,
+	scanner.RIGHT_BRACE: MISSING_RIGHT_BRACE,
 }
 
-func NewError(message string, sync bool, prs *parser) ParsingError {
+var parsingHints = map[ErrorKind]string{
+	MISSING_SEMICOLON:         "Every statement has to end with ';'.",
+	MISSING_RIGHT_PAREN:       "Check that every '(' is closed with a ')'.",
+	MISSING_RIGHT_BRACE:       "Check that every '{' is closed with a '}'.",
+	INVALID_ASSIGNMENT_TARGET: "Only variables and fields of instances can be assigned.",
+}
 
-	err := ParsingError{prs.current(), prs.head, message, nil}
-	if sync {
-		prs.synchronize()
-		err.SyncEnd = &(*prs.tokens)[prs.head-1]
+func (pe ParsingError) Diagnostic() diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{
+		Code:    diagnostic.PARSING_ERROR,
+		Message: pe.Message,
+		Line:    pe.Token.Line,
+		Offset:  pe.Token.Position,
+		Length:  pe.Token.Length,
+		Hint:    parsingHints[pe.Kind],
 	}
-	return err
 }
 
 // Indicates issues with the PARSER code is erroneous
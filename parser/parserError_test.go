@@ -1,10 +1,51 @@
 package parser
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/scanner"
+)
 
 func TestNewError(t *testing.T) {
+	token := scanner.Token{Line: 3, Column: 5, Type: scanner.IDENTIFIER, Lexeme: "x", Position: 12}
+	assert.Equal(t, ParsingError{Token: token, Message: "msg"}, NewError(token, "msg"))
 }
 func TestParsingError_Error(t *testing.T) {
+	err := ParsingError{Token: scanner.Token{Line: 3, Column: 5, Type: scanner.IDENTIFIER, Lexeme: "x", Position: 12}, Message: "Expect expression."}
+	assert.EqualError(t, err, "[Line 3] ParsingError at 'x' (Column 5): Expect expression.")
+
+	err = ParsingError{Token: scanner.Token{Line: 4, Column: 3, Type: scanner.EOF, Lexeme: "EOF", Position: 20}, Message: "Expect expression."}
+	assert.EqualError(t, err, "[Line 4] ParsingError at end (Column 3): Expect expression.")
+}
+func TestParsingError_Diagnostic(t *testing.T) {
+	tests := []struct {
+		source string
+		kind   ErrorKind
+		hint   string
+	}{
+		{"print 1", MISSING_SEMICOLON, "Every statement has to end with ';'."},
+		{"print (1;", MISSING_RIGHT_PAREN, "Check that every '(' is closed with a ')'."},
+		{"{ print 1;", MISSING_RIGHT_BRACE, "Check that every '{' is closed with a '}'."},
+		{"1 = 2;", INVALID_ASSIGNMENT_TARGET, "Only variables and fields of instances can be assigned."},
+		{"print ;", INVALID_SYNTAX, ""},
+	}
+
+	for _, tt := range tests {
+		scnr := scanner.Scanner{}
+		scnr.Scan(tt.source)
+		prs := NewParser(&scnr.Tokens)
+		prs.Parse()
+		if assert.Len(t, prs.Errors(), 1, tt.source) {
+			err := prs.Errors()[0].(ParsingError)
+			assert.Equal(t, tt.kind, err.Kind, tt.source)
+			assert.Equal(t, tt.hint, err.Diagnostic().Hint, tt.source)
+		}
+	}
+
+	err := ParsingError{Kind: MISSING_SEMICOLON, Message: "Expect ';' after value."}
+	assert.Equal(t, "Every statement has to end with ';'.", err.Diagnostic().Hint, "Expecting the hint to depend on the kind only.")
 }
 func TestInvalidArgumentError_Error(t *testing.T) {
 }
@@ -1,12 +1,14 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/th-lange/glox/expression"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
 )
 
 type BinaryTest struct {
@@ -202,6 +204,35 @@ func TestParser_Primary_Simple(t *testing.T) {
 
 }
 
+func TestParser_Primary_Interpolation(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Column: 2, Type: scanner.INTERPOLATION, Lexeme: "Hello ", Literal: "Hello "},
+		{Line: 1, Column: 10, Type: scanner.IDENTIFIER, Lexeme: "name"},
+		{Line: 1, Column: 15, Type: scanner.STRING, Lexeme: "!", Literal: "!"},
+		{Line: 1, Column: 17, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs := NewParser(&input)
+
+	expected := expression.Interpolation{Parts: []expression.Expression{
+		expression.Literal{Value: input[0]},
+		expression.Variable{Name: input[1]},
+		expression.Literal{Value: input[2]},
+	}}
+	assert.Equal(t, expected, prs.primary(), "Expecting the segments and the embedded expression as parts of the interpolation.")
+}
+
+func TestParser_Primary_UnterminatedInterpolation(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.INTERPOLATION, Lexeme: "a", Literal: "a"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "b"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs := NewParser(&input)
+
+	assert.PanicsWithValue(t, NewError(input[2], "Could not find expected Token: STRING"), func() { prs.primary() })
+}
+
 func TestParser_Primary_Grouping_OK(t *testing.T) {
 
 	input := []scanner.Token{
@@ -449,6 +480,7 @@ func TestParser_Synchronize(t *testing.T) {
 	prs := NewParser(&input)
 
 	prs.synchronize()
+	assert.Equal(t, 0, prs.head, "Expecting synchronize() not to move on an empty parser")
 
 	// Test Case 2: Parser with no break conditions
 	input = []scanner.Token{
@@ -459,7 +491,7 @@ func TestParser_Synchronize(t *testing.T) {
 	prs = NewParser(&input)
 
 	prs.synchronize()
-	assert.Equal(t, 3, prs.head, "Expecting synchronize() to walk to the end if no break conditions are found: CLASS, FUN, VAR, FOR, IF, WHILE, PRINT, RETURN")
+	assert.Equal(t, 3, prs.head, "Expecting synchronize() to walk to the end if no break conditions are found: SEMICOLON, CLASS, FUN, VAR, FOR, IF, WHILE, PRINT, RETURN")
 
 	// Test Case 3: Last element is a break condition
 	input = []scanner.Token{
@@ -470,9 +502,9 @@ func TestParser_Synchronize(t *testing.T) {
 	}
 	prs = NewParser(&input)
 	prs.synchronize()
-	assert.Equal(t, 4, prs.head, "Expecting synchronize() to walk to the end if no break conditions are found: CLASS, FUN, VAR, FOR, IF, WHILE, PRINT, RETURN")
+	assert.Equal(t, 3, prs.head, "Expecting synchronize() to stop before a keyword starting a statement")
 
-	// Test Case 4: Next element is a break condition
+	// Test Case 4: The erroneous token is a break condition
 	input = []scanner.Token{
 		{Line: 10, Type: scanner.FUN, Lexeme: "fun"},
 		{Line: 10, Type: scanner.LEFT_PAREN, Lexeme: "("},
@@ -481,12 +513,603 @@ func TestParser_Synchronize(t *testing.T) {
 	}
 	prs = NewParser(&input)
 	prs.synchronize()
-	assert.Equal(t, 1, prs.head, "Expecting synchronize() to walk to the end if no break conditions are found: CLASS, FUN, VAR, FOR, IF, WHILE, PRINT, RETURN")
+	assert.Equal(t, 4, prs.head, "Expecting synchronize() to skip the erroneous token, even if it starts a statement")
+
+	// Test Case 5: Statement ends with a semicolon
+	input = []scanner.Token{
+		{Line: 10, Type: scanner.IDENTIFIER, Lexeme: "a"},
+		{Line: 10, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 10, Type: scanner.IDENTIFIER, Lexeme: "b"},
+		{Line: 10, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs = NewParser(&input)
+	prs.synchronize()
+	assert.Equal(t, 2, prs.head, "Expecting synchronize() to stop after a semicolon")
+	prs.synchronize()
+	assert.Equal(t, 3, prs.head, "Expecting synchronize() to stop at EOF")
+	prs.synchronize()
+	assert.Equal(t, 3, prs.head, "Expecting synchronize() not to move past EOF")
 }
 
 func TestParser_Parse(t *testing.T) {
 	input, expected := getParserResult()
+	input = append(input,
+		scanner.Token{Line: 10, Type: scanner.SEMICOLON, Lexeme: ";"},
+		scanner.Token{Line: 10, Type: scanner.EOF, Lexeme: "EOF"},
+	)
 	prs := NewParser(&input)
 	result := prs.Parse()
-	assert.Equal(t, expected, result, "Expecting a correct output for parsing complex expressions.")
+	assert.Equal(t, []statement.Stmt{statement.Expression{Expr: expected}}, result, "Expecting a correct output for parsing complex expressions.")
+}
+
+func TestParser_Parse_Statements(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print"},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 2, Type: scanner.STRING, Lexeme: "foo"},
+		{Line: 2, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 2, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+
+	expected := []statement.Stmt{
+		statement.Print{Expr: expression.Literal{Value: input[1]}},
+		statement.Expression{Expr: expression.Literal{Value: input[3]}},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.Parse(), "Expecting a print and an expression statement.")
+}
+
+func TestParser_Parse_Empty(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs := NewParser(&input)
+	result := prs.Parse()
+	assert.NotNil(t, result, "Expecting an empty program not to be reported as error.")
+	assert.Empty(t, result, "Expecting no statements for an empty program.")
+}
+
+const streamedProgram = `var a = "x";
+class B < A { init(n) { this.n = n * 2; } }
+fun f(x) { for (var i = 0; i < 3; i = i + 1) { print "${x} ${i}"; } }
+if (a == nil) print 1; else { print -2; }
+`
+
+func TestParser_NewStreamParser(t *testing.T) {
+	scnr := scanner.Scanner{}
+	scnr.Scan(streamedProgram)
+	expected := NewParser(&scnr.Tokens).Parse()
+
+	prs := NewStreamParser(scanner.NewStreamScanner(strings.NewReader(streamedProgram)))
+	assert.Equal(t, expected, prs.Parse(), "Expecting the stream to be parsed like the scanned tokens.")
+	assert.Empty(t, prs.Errors())
+}
+
+func TestParser_NewStreamParser_Errors(t *testing.T) {
+	prs := NewStreamParser(scanner.NewStreamScanner(strings.NewReader("print 1 @;\nprint ;\nprint 3;")))
+
+	stmt, ok := prs.ParseDeclaration()
+	assert.True(t, ok)
+	assert.NotNil(t, stmt, "Expecting the scanner error not to break the statement.")
+	assert.Len(t, prs.Errors(), 1, "Expecting the error of the scanner to be recorded.")
+	assert.IsType(t, scanner.ScannerError{}, prs.Errors()[0])
+
+	stmt, ok = prs.ParseDeclaration()
+	assert.True(t, ok)
+	assert.Nil(t, stmt, "Expecting nil for an erroneous declaration.")
+	assert.EqualError(t, prs.Errors()[1], "[Line 2] ParsingError at ';' (Column 7): Expect expression.")
+
+	stmt, ok = prs.ParseDeclaration()
+	assert.True(t, ok)
+	assert.NotNil(t, stmt, "Expecting the parsing to continue after the error.")
+
+	_, ok = prs.ParseDeclaration()
+	assert.False(t, ok, "Expecting the end of the tokens.")
+}
+
+func TestParser_NewStreamParser_DiscardsParsedTokens(t *testing.T) {
+	source := strings.Repeat("print 1 + 2;\n", 1000)
+	prs := NewStreamParser(scanner.NewStreamScanner(strings.NewReader(source)))
+
+	count := 0
+	for {
+		_, ok := prs.ParseDeclaration()
+		if !ok {
+			break
+		}
+		assert.LessOrEqual(t, len(*prs.tokens), 7, "Expecting the tokens of parsed declarations to be dropped.")
+		count += 1
+	}
+	assert.Equal(t, 1000, count)
+	assert.Empty(t, prs.Errors())
+}
+
+func TestParser_Parse_MissingSemicolon(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print"},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs := NewParser(&input)
+	assert.Nil(t, prs.Parse(), "Expecting nil if the program could not be parsed.")
+}
+
+func TestParser_Parse_ReplMode(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs := NewParser(&input)
+	prs.EnableReplMode()
+
+	expected := []statement.Stmt{
+		statement.Print{Expr: expression.Literal{Value: input[0]}},
+	}
+	assert.Equal(t, expected, prs.Parse(), "Expecting a trailing expression to be printed in repl mode.")
+}
+
+func TestParser_Parse_VarDeclaration(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.VAR, Lexeme: "var"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "x"},
+		{Line: 1, Type: scanner.EQUAL, Lexeme: "="},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 2, Type: scanner.VAR, Lexeme: "var"},
+		{Line: 2, Type: scanner.IDENTIFIER, Lexeme: "y"},
+		{Line: 2, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 2, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+
+	expected := []statement.Stmt{
+		statement.Var{Name: input[1], Initializer: expression.Literal{Value: input[3]}},
+		statement.Var{Name: input[6]},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.Parse(), "Expecting variable declarations with and without initializer.")
+}
+
+func TestParser_Assignment(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "x"},
+		{Line: 1, Type: scanner.EQUAL, Lexeme: "="},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "y"},
+		{Line: 1, Type: scanner.EQUAL, Lexeme: "="},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "z"},
+	}
+
+	expected := expression.Assign{
+		Name: input[0],
+		Value: expression.Assign{
+			Name:  input[2],
+			Value: expression.Variable{Name: input[4]},
+		},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.assignment(), "Expecting assignments to be right associative.")
+}
+
+func TestParser_Assignment_InvalidTarget(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.EQUAL, Lexeme: "="},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "2"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs := NewParser(&input)
+	assert.Nil(t, prs.Parse(), "Expecting only variables to be valid assignment targets.")
+}
+
+func TestParser_Parse_Block(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.LEFT_BRACE, Lexeme: "{"},
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print"},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.LEFT_BRACE, Lexeme: "{"},
+		{Line: 1, Type: scanner.RIGHT_BRACE, Lexeme: "}"},
+		{Line: 1, Type: scanner.RIGHT_BRACE, Lexeme: "}"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+
+	expected := []statement.Stmt{
+		statement.Block{Statements: []statement.Stmt{
+			statement.Print{Expr: expression.Literal{Value: input[2]}},
+			statement.Block{Statements: []statement.Stmt{}},
+		}},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.Parse(), "Expecting nested blocks to be parsed.")
+}
+
+func TestParser_Parse_UnterminatedBlock(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.LEFT_BRACE, Lexeme: "{"},
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print"},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs := NewParser(&input)
+	assert.Nil(t, prs.Parse(), "Expecting a block without closing brace to be an error.")
+}
+
+func TestParser_Or_And(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.TRUE, Lexeme: "true"},
+		{Line: 1, Type: scanner.OR, Lexeme: "or"},
+		{Line: 1, Type: scanner.FALSE, Lexeme: "false"},
+		{Line: 1, Type: scanner.AND, Lexeme: "and"},
+		{Line: 1, Type: scanner.NIL, Lexeme: "nil"},
+	}
+
+	expected := expression.Logical{
+		Left:     expression.Literal{Value: input[0]},
+		Operator: input[1],
+		Right: expression.Logical{
+			Left:     expression.Literal{Value: input[2]},
+			Operator: input[3],
+			Right:    expression.Literal{Value: input[4]},
+		},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.or(), "Expecting and to bind tighter than or.")
+}
+
+func TestParser_Parse_If(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.IF, Lexeme: "if"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.TRUE, Lexeme: "true"},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")"},
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print"},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.ELSE, Lexeme: "else"},
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print"},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "2"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+
+	expected := []statement.Stmt{
+		statement.If{
+			Condition:  expression.Literal{Value: input[2]},
+			ThenBranch: statement.Print{Expr: expression.Literal{Value: input[5]}},
+			ElseBranch: statement.Print{Expr: expression.Literal{Value: input[9]}},
+		},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.Parse(), "Expecting an if statement with else branch.")
+}
+
+func TestParser_Parse_For(t *testing.T) {
+	// for (var i = 0; i < 1; i = i + 1) print i;
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.FOR, Lexeme: "for"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.VAR, Lexeme: "var"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "i"},
+		{Line: 1, Type: scanner.EQUAL, Lexeme: "="},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "0"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "i"},
+		{Line: 1, Type: scanner.LESS, Lexeme: "<"},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "i"},
+		{Line: 1, Type: scanner.EQUAL, Lexeme: "="},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "i"},
+		{Line: 1, Type: scanner.PLUS, Lexeme: "+"},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")"},
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "i"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+
+	expected := []statement.Stmt{
+		statement.Block{Statements: []statement.Stmt{
+			statement.Var{Name: input[3], Initializer: expression.Literal{Value: input[5]}},
+			statement.While{
+				Condition: expression.Binary{Left: expression.Variable{Name: input[7]}, Operator: input[8], Right: expression.Literal{Value: input[9]}},
+				Body: statement.Block{Statements: []statement.Stmt{
+					statement.Print{Expr: expression.Variable{Name: input[18]}},
+					statement.Expression{Expr: expression.Assign{
+						Name:  input[11],
+						Value: expression.Binary{Left: expression.Variable{Name: input[13]}, Operator: input[14], Right: expression.Literal{Value: input[15]}},
+					}},
+				}},
+			},
+		}},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.Parse(), "Expecting the for loop to be desugared into a while loop.")
+}
+
+func TestParser_Parse_ForWithoutClauses(t *testing.T) {
+	// for (;;) print 1;
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.FOR, Lexeme: "for"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";", Position: 5},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";", Position: 6},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")"},
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print"},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+
+	expected := []statement.Stmt{
+		statement.While{
+			Condition: expression.Literal{Value: scanner.Token{Line: 1, Type: scanner.TRUE, Lexeme: "true", Position: 6}},
+			Body:      statement.Print{Expr: expression.Literal{Value: input[6]}},
+		},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.Parse(), "Expecting a missing condition to loop forever.")
+}
+
+func TestParser_Parse_Function(t *testing.T) {
+	// fun add(a, b) { return a; }
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.FUN, Lexeme: "fun"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "add"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "a"},
+		{Line: 1, Type: scanner.COMMA, Lexeme: ","},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "b"},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")"},
+		{Line: 1, Type: scanner.LEFT_BRACE, Lexeme: "{"},
+		{Line: 1, Type: scanner.RETURN, Lexeme: "return"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "a"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.RIGHT_BRACE, Lexeme: "}"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+
+	expected := []statement.Stmt{
+		statement.Function{
+			Name:   input[1],
+			Params: []scanner.Token{input[3], input[5]},
+			Body: []statement.Stmt{
+				statement.Return{Keyword: input[8], Value: expression.Variable{Name: input[9]}},
+			},
+		},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.Parse(), "Expecting a function declaration with parameters and return.")
+}
+
+func TestParser_Call(t *testing.T) {
+	// f(1)()
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "f"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")", Position: 3},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")", Position: 5},
+	}
+
+	expected := expression.Call{
+		Callee: expression.Call{
+			Callee:    expression.Variable{Name: input[0]},
+			Paren:     input[3],
+			Arguments: []expression.Expression{expression.Literal{Value: input[2]}},
+		},
+		Paren:     input[5],
+		Arguments: []expression.Expression{},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.call(), "Expecting chained calls to be parsed left to right.")
+}
+
+func TestParser_Call_TooManyArguments(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "f"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+	}
+	for i := 0; i <= maxArguments; i++ {
+		input = append(input, scanner.Token{Line: 1, Type: scanner.NUMBER, Lexeme: "1"}, scanner.Token{Line: 1, Type: scanner.COMMA, Lexeme: ","})
+	}
+	input = append(input,
+		scanner.Token{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		scanner.Token{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")"},
+		scanner.Token{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		scanner.Token{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	)
+	prs := NewParser(&input)
+	assert.Nil(t, prs.Parse(), "Expecting more than 255 arguments to be an error.")
+	assert.Len(t, prs.Errors(), 1, "Expecting the error to be reported once.")
+}
+
+func TestParser_Parse_Class(t *testing.T) {
+	// class Foo { bar() { this.x = 1; } }
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.CLASS, Lexeme: "class"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "Foo"},
+		{Line: 1, Type: scanner.LEFT_BRACE, Lexeme: "{"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "bar"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")"},
+		{Line: 1, Type: scanner.LEFT_BRACE, Lexeme: "{"},
+		{Line: 1, Type: scanner.THIS, Lexeme: "this"},
+		{Line: 1, Type: scanner.DOT, Lexeme: "."},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "x"},
+		{Line: 1, Type: scanner.EQUAL, Lexeme: "="},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.RIGHT_BRACE, Lexeme: "}"},
+		{Line: 1, Type: scanner.RIGHT_BRACE, Lexeme: "}"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+
+	expected := []statement.Stmt{
+		statement.Class{
+			Name: input[1],
+			Methods: []statement.Function{
+				{
+					Name:   input[3],
+					Params: []scanner.Token{},
+					Body: []statement.Stmt{
+						statement.Expression{Expr: expression.Set{
+							Object: expression.This{Keyword: input[7]},
+							Name:   input[9],
+							Value:  expression.Literal{Value: input[11]},
+						}},
+					},
+				},
+			},
+		},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.Parse(), "Expecting a class with a method setting a field.")
+}
+
+func TestParser_Call_Get(t *testing.T) {
+	// a.b(1).c
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "a"},
+		{Line: 1, Type: scanner.DOT, Lexeme: "."},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "b"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1"},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")"},
+		{Line: 1, Type: scanner.DOT, Lexeme: "."},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "c"},
+	}
+
+	expected := expression.Get{
+		Object: expression.Call{
+			Callee:    expression.Get{Object: expression.Variable{Name: input[0]}, Name: input[2]},
+			Paren:     input[5],
+			Arguments: []expression.Expression{expression.Literal{Value: input[4]}},
+		},
+		Name: input[7],
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.call(), "Expecting property access and calls to chain.")
+}
+
+func TestParser_Parse_Subclass(t *testing.T) {
+	// class B < A { m() { super.m(); } }
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.CLASS, Lexeme: "class"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "B"},
+		{Line: 1, Type: scanner.LESS, Lexeme: "<"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "A"},
+		{Line: 1, Type: scanner.LEFT_BRACE, Lexeme: "{"},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "m"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")"},
+		{Line: 1, Type: scanner.LEFT_BRACE, Lexeme: "{"},
+		{Line: 1, Type: scanner.SUPER, Lexeme: "super"},
+		{Line: 1, Type: scanner.DOT, Lexeme: "."},
+		{Line: 1, Type: scanner.IDENTIFIER, Lexeme: "m"},
+		{Line: 1, Type: scanner.LEFT_PAREN, Lexeme: "("},
+		{Line: 1, Type: scanner.RIGHT_PAREN, Lexeme: ")"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.RIGHT_BRACE, Lexeme: "}"},
+		{Line: 1, Type: scanner.RIGHT_BRACE, Lexeme: "}"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+
+	expected := []statement.Stmt{
+		statement.Class{
+			Name:       input[1],
+			Superclass: &expression.Variable{Name: input[3]},
+			Methods: []statement.Function{
+				{
+					Name:   input[5],
+					Params: []scanner.Token{},
+					Body: []statement.Stmt{
+						statement.Expression{Expr: expression.Call{
+							Callee:    expression.Super{Keyword: input[9], Method: input[11]},
+							Paren:     input[13],
+							Arguments: []expression.Expression{},
+						}},
+					},
+				},
+			},
+		},
+	}
+	prs := NewParser(&input)
+	assert.Equal(t, expected, prs.Parse(), "Expecting a subclass calling a superclass method.")
+}
+
+func TestParser_Parse_InheritFromItself(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Column: 1, Type: scanner.CLASS, Lexeme: "class"},
+		{Line: 1, Column: 7, Type: scanner.IDENTIFIER, Lexeme: "A"},
+		{Line: 1, Column: 9, Type: scanner.LESS, Lexeme: "<"},
+		{Line: 1, Column: 11, Type: scanner.IDENTIFIER, Lexeme: "A"},
+		{Line: 1, Column: 13, Type: scanner.LEFT_BRACE, Lexeme: "{"},
+		{Line: 1, Column: 14, Type: scanner.RIGHT_BRACE, Lexeme: "}"},
+		{Line: 1, Column: 15, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs := NewParser(&input)
+	assert.Nil(t, prs.Parse(), "Expecting a class inheriting from itself to be an error.")
+	assert.EqualError(t, prs.Errors()[0], "[Line 1] ParsingError at 'A' (Column 11): A class can't inherit from itself.")
+}
+
+func TestParser_Primary_SuperWithoutMethod(t *testing.T) {
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.SUPER, Lexeme: "super"},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";"},
+		{Line: 1, Type: scanner.EOF, Lexeme: "EOF"},
+	}
+	prs := NewParser(&input)
+	assert.Nil(t, prs.Parse(), "Expecting super without a method access to be an error.")
+}
+
+func TestParser_Parse_ReportsAllErrors(t *testing.T) {
+	// print 1 print 2;
+	// var = 3;
+	// { 1 + ; }
+	// a + b = 4;
+	// print 5
+	input := []scanner.Token{
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print", Column: 1},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "1", Column: 7},
+		{Line: 1, Type: scanner.PRINT, Lexeme: "print", Column: 9},
+		{Line: 1, Type: scanner.NUMBER, Lexeme: "2", Column: 15},
+		{Line: 1, Type: scanner.SEMICOLON, Lexeme: ";", Column: 16},
+		{Line: 2, Type: scanner.VAR, Lexeme: "var", Column: 1},
+		{Line: 2, Type: scanner.EQUAL, Lexeme: "=", Column: 5},
+		{Line: 2, Type: scanner.NUMBER, Lexeme: "3", Column: 7},
+		{Line: 2, Type: scanner.SEMICOLON, Lexeme: ";", Column: 8},
+		{Line: 3, Type: scanner.LEFT_BRACE, Lexeme: "{", Column: 1},
+		{Line: 3, Type: scanner.NUMBER, Lexeme: "1", Column: 3},
+		{Line: 3, Type: scanner.PLUS, Lexeme: "+", Column: 5},
+		{Line: 3, Type: scanner.SEMICOLON, Lexeme: ";", Column: 7},
+		{Line: 3, Type: scanner.RIGHT_BRACE, Lexeme: "}", Column: 9},
+		{Line: 4, Type: scanner.IDENTIFIER, Lexeme: "a", Column: 1},
+		{Line: 4, Type: scanner.PLUS, Lexeme: "+", Column: 3},
+		{Line: 4, Type: scanner.IDENTIFIER, Lexeme: "b", Column: 5},
+		{Line: 4, Type: scanner.EQUAL, Lexeme: "=", Column: 7},
+		{Line: 4, Type: scanner.NUMBER, Lexeme: "4", Column: 9},
+		{Line: 4, Type: scanner.SEMICOLON, Lexeme: ";", Column: 10},
+		{Line: 5, Type: scanner.PRINT, Lexeme: "print", Column: 1},
+		{Line: 5, Type: scanner.NUMBER, Lexeme: "5", Column: 7},
+		{Line: 5, Type: scanner.EOF, Lexeme: "EOF", Column: 8},
+	}
+	prs := NewParser(&input)
+	assert.Nil(t, prs.Parse(), "Expecting nil if the program could not be parsed.")
+
+	messages := make([]string, 0, 5)
+	for _, err := range prs.Errors() {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		"[Line 1] ParsingError at 'print' (Column 9): Could not find expected Token: SEMICOLON",
+		"[Line 2] ParsingError at '=' (Column 5): Could not find expected Token: IDENTIFIER",
+		"[Line 3] ParsingError at ';' (Column 7): Expect expression.",
+		"[Line 4] ParsingError at '=' (Column 7): Invalid assignment target.",
+		"[Line 5] ParsingError at end (Column 8): Could not find expected Token: SEMICOLON",
+	}, messages, "Expecting every erroneous statement to be reported once.")
 }
@@ -0,0 +1,307 @@
+package resolver
+
+import (
+	"github.com/th-lange/glox/expression"
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+)
+
+type functionType int
+
+const (
+	NONE functionType = iota
+	FUNCTION
+	INITIALIZER
+	METHOD
+)
+
+type classType int
+
+const (
+	NO_CLASS classType = iota
+	CLASS
+	SUBCLASS
+)
+
+// Resolver is a static pass over the parsed statements, run before the execution.
+// For every local variable it records, how many scopes lie between the usage and the declaration.
+// Variables without an entry in Locals are globals.
+type Resolver struct {
+	Errors          []error
+	HadError        bool
+	Locals          map[scanner.Token]int
+	scopes          []map[string]bool
+	currentFunction functionType
+	currentClass    classType
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{
+		Errors:          make([]error, 0, 4),
+		Locals:          make(map[scanner.Token]int),
+		scopes:          make([]map[string]bool, 0, 8),
+		currentFunction: NONE,
+		currentClass:    NO_CLASS,
+	}
+}
+
+func (rslvr *Resolver) Resolve(statements []statement.Stmt) {
+	for _, stmt := range statements {
+		rslvr.resolveStmt(stmt)
+	}
+}
+
+func (rslvr *Resolver) resolveStmt(stmt statement.Stmt) {
+	stmt.Accept(rslvr)
+}
+
+func (rslvr *Resolver) resolveExpression(expr expression.Expression) {
+	expr.Accept(rslvr)
+}
+
+func (rslvr *Resolver) appendError(token scanner.Token, message string) {
+	rslvr.appendKindError(INVALID_SEMANTICS, token, message)
+}
+
+// appendKindError reports an error, which has a hint for its kind
+func (rslvr *Resolver) appendKindError(kind ErrorKind, token scanner.Token, message string) {
+	rslvr.HadError = true
+	rslvr.Errors = append(rslvr.Errors, ResolverError{Kind: kind, Token: token, Message: message})
+}
+
+func (rslvr *Resolver) beginScope() {
+	rslvr.scopes = append(rslvr.scopes, make(map[string]bool))
+}
+
+func (rslvr *Resolver) endScope() {
+	rslvr.scopes = rslvr.scopes[:len(rslvr.scopes)-1]
+}
+
+// declare adds the variable to the innermost scope, marked as not ready for use
+func (rslvr *Resolver) declare(name scanner.Token) {
+	if len(rslvr.scopes) == 0 {
+		return
+	}
+	scope := rslvr.scopes[len(rslvr.scopes)-1]
+	if _, ok := scope[name.Lexeme]; ok {
+		rslvr.appendKindError(DUPLICATE_VARIABLE, name, "Already a variable with this name in this scope.")
+	}
+	scope[name.Lexeme] = false
+}
+
+// define marks the variable as initialized and ready for use
+func (rslvr *Resolver) define(name scanner.Token) {
+	if len(rslvr.scopes) == 0 {
+		return
+	}
+	rslvr.scopes[len(rslvr.scopes)-1][name.Lexeme] = true
+}
+
+func (rslvr *Resolver) resolveLocal(name scanner.Token) {
+	for i := len(rslvr.scopes) - 1; i >= 0; i-- {
+		if _, ok := rslvr.scopes[i][name.Lexeme]; ok {
+			rslvr.Locals[name] = len(rslvr.scopes) - 1 - i
+			return
+		}
+	}
+}
+
+func (rslvr *Resolver) resolveFunction(function statement.Function, fnType functionType) {
+	enclosingFunction := rslvr.currentFunction
+	rslvr.currentFunction = fnType
+
+	rslvr.beginScope()
+	for _, param := range function.Params {
+		rslvr.declare(param)
+		rslvr.define(param)
+	}
+	rslvr.Resolve(function.Body)
+	rslvr.endScope()
+
+	rslvr.currentFunction = enclosingFunction
+}
+
+func (rslvr *Resolver) VisitBlockStmt(stmt statement.Block) interface{} {
+	rslvr.beginScope()
+	rslvr.Resolve(stmt.Statements)
+	rslvr.endScope()
+	return nil
+}
+
+func (rslvr *Resolver) VisitClassStmt(stmt statement.Class) interface{} {
+	enclosingClass := rslvr.currentClass
+	rslvr.currentClass = CLASS
+
+	rslvr.declare(stmt.Name)
+	rslvr.define(stmt.Name)
+
+	if stmt.Superclass != nil {
+		rslvr.currentClass = SUBCLASS
+		rslvr.resolveExpression(*stmt.Superclass)
+		// the methods of a subclass close over an extra scope holding "super"
+		rslvr.beginScope()
+		rslvr.scopes[len(rslvr.scopes)-1]["super"] = true
+	}
+
+	rslvr.beginScope()
+	rslvr.scopes[len(rslvr.scopes)-1]["this"] = true
+	for _, method := range stmt.Methods {
+		fnType := METHOD
+		if method.Name.Lexeme == "init" {
+			fnType = INITIALIZER
+		}
+		rslvr.resolveFunction(method, fnType)
+	}
+	rslvr.endScope()
+	if stmt.Superclass != nil {
+		rslvr.endScope()
+	}
+
+	rslvr.currentClass = enclosingClass
+	return nil
+}
+
+func (rslvr *Resolver) VisitExpressionStmt(stmt statement.Expression) interface{} {
+	rslvr.resolveExpression(stmt.Expr)
+	return nil
+}
+
+func (rslvr *Resolver) VisitFunctionStmt(stmt statement.Function) interface{} {
+	// defined before the body is resolved, so the function can refer to itself
+	rslvr.declare(stmt.Name)
+	rslvr.define(stmt.Name)
+	rslvr.resolveFunction(stmt, FUNCTION)
+	return nil
+}
+
+func (rslvr *Resolver) VisitIfStmt(stmt statement.If) interface{} {
+	rslvr.resolveExpression(stmt.Condition)
+	rslvr.resolveStmt(stmt.ThenBranch)
+	if stmt.ElseBranch != nil {
+		rslvr.resolveStmt(stmt.ElseBranch)
+	}
+	return nil
+}
+
+func (rslvr *Resolver) VisitPrintStmt(stmt statement.Print) interface{} {
+	rslvr.resolveExpression(stmt.Expr)
+	return nil
+}
+
+func (rslvr *Resolver) VisitReturnStmt(stmt statement.Return) interface{} {
+	if rslvr.currentFunction == NONE {
+		rslvr.appendError(stmt.Keyword, "Can't return from top-level code.")
+	}
+	if stmt.Value != nil {
+		if rslvr.currentFunction == INITIALIZER {
+			rslvr.appendKindError(RETURN_VALUE_FROM_INITIALIZER, stmt.Keyword, "Can't return a value from an initializer.")
+		}
+		rslvr.resolveExpression(stmt.Value)
+	}
+	return nil
+}
+
+func (rslvr *Resolver) VisitVarStmt(stmt statement.Var) interface{} {
+	rslvr.declare(stmt.Name)
+	if stmt.Initializer != nil {
+		rslvr.resolveExpression(stmt.Initializer)
+	}
+	rslvr.define(stmt.Name)
+	return nil
+}
+
+func (rslvr *Resolver) VisitWhileStmt(stmt statement.While) interface{} {
+	rslvr.resolveExpression(stmt.Condition)
+	rslvr.resolveStmt(stmt.Body)
+	return nil
+}
+
+func (rslvr *Resolver) VisitAssign(expression expression.Assign) interface{} {
+	rslvr.resolveExpression(expression.Value)
+	rslvr.resolveLocal(expression.Name)
+	return nil
+}
+
+func (rslvr *Resolver) VisitBinary(expression expression.Binary) interface{} {
+	rslvr.resolveExpression(expression.Left)
+	rslvr.resolveExpression(expression.Right)
+	return nil
+}
+
+func (rslvr *Resolver) VisitCall(expression expression.Call) interface{} {
+	rslvr.resolveExpression(expression.Callee)
+	for _, argument := range expression.Arguments {
+		rslvr.resolveExpression(argument)
+	}
+	return nil
+}
+
+func (rslvr *Resolver) VisitGet(expression expression.Get) interface{} {
+	rslvr.resolveExpression(expression.Object)
+	return nil
+}
+
+func (rslvr *Resolver) VisitGrouping(expression expression.Grouping) interface{} {
+	rslvr.resolveExpression(expression.Expr)
+	return nil
+}
+
+func (rslvr *Resolver) VisitLiteral(expression expression.Literal) interface{} {
+	return nil
+}
+
+func (rslvr *Resolver) VisitLogical(expression expression.Logical) interface{} {
+	rslvr.resolveExpression(expression.Left)
+	rslvr.resolveExpression(expression.Right)
+	return nil
+}
+
+func (rslvr *Resolver) VisitSet(expression expression.Set) interface{} {
+	rslvr.resolveExpression(expression.Value)
+	rslvr.resolveExpression(expression.Object)
+	return nil
+}
+
+func (rslvr *Resolver) VisitThis(expression expression.This) interface{} {
+	if rslvr.currentClass == NO_CLASS {
+		rslvr.appendError(expression.Keyword, "Can't use 'this' outside of a class.")
+		return nil
+	}
+	rslvr.resolveLocal(expression.Keyword)
+	return nil
+}
+
+func (rslvr *Resolver) VisitSuper(expression expression.Super) interface{} {
+	switch rslvr.currentClass {
+	case NO_CLASS:
+		rslvr.appendError(expression.Keyword, "Can't use 'super' outside of a class.")
+		return nil
+	case CLASS:
+		rslvr.appendError(expression.Keyword, "Can't use 'super' in a class with no superclass.")
+		return nil
+	}
+	rslvr.resolveLocal(expression.Keyword)
+	return nil
+}
+
+func (rslvr *Resolver) VisitInterpolation(expression expression.Interpolation) interface{} {
+	for _, part := range expression.Parts {
+		rslvr.resolveExpression(part)
+	}
+	return nil
+}
+
+func (rslvr *Resolver) VisitUnary(expression expression.Unary) interface{} {
+	rslvr.resolveExpression(expression.Right)
+	return nil
+}
+
+func (rslvr *Resolver) VisitVariable(expression expression.Variable) interface{} {
+	if len(rslvr.scopes) > 0 {
+		if initialized, ok := rslvr.scopes[len(rslvr.scopes)-1][expression.Name.Lexeme]; ok && !initialized {
+			rslvr.appendKindError(READ_IN_OWN_INITIALIZER, expression.Name, "Can't read local variable in its own initializer.")
+		}
+	}
+	rslvr.resolveLocal(expression.Name)
+	return nil
+}
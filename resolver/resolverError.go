@@ -0,0 +1,46 @@
+package resolver
+
+import (
+	"strconv"
+
+	"github.com/th-lange/glox/diagnostic"
+	"github.com/th-lange/glox/scanner"
+)
+
+// ErrorKind identifies a ResolverError independent of its message, errors without a hint keep the zero kind
+type ErrorKind int
+
+const (
+	INVALID_SEMANTICS ErrorKind = iota
+	READ_IN_OWN_INITIALIZER
+	DUPLICATE_VARIABLE
+	RETURN_VALUE_FROM_INITIALIZER
+)
+
+// Indicates that the PARSED code is semantically erroneous, e.g. a return outside of a function
+type ResolverError struct {
+	Kind    ErrorKind
+	Token   scanner.Token
+	Message string
+}
+
+func (re ResolverError) Error() string {
+	return "[Line " + strconv.Itoa(re.Token.Line) + "] ResolverError at '" + re.Token.Lexeme + "' (Column " + strconv.Itoa(re.Token.Column) + "): " + re.Message
+}
+
+var resolverHints = map[ErrorKind]string{
+	READ_IN_OWN_INITIALIZER:       "Use a different name, if a variable of an outer scope should be read.",
+	DUPLICATE_VARIABLE:            "Assign the variable instead of declaring it again.",
+	RETURN_VALUE_FROM_INITIALIZER: "An initializer always returns the instance, use 'return;' to leave it early.",
+}
+
+func (re ResolverError) Diagnostic() diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{
+		Code:    diagnostic.RESOLVER_ERROR,
+		Message: re.Message,
+		Line:    re.Token.Line,
+		Offset:  re.Token.Position,
+		Length:  re.Token.Length,
+		Hint:    resolverHints[re.Kind],
+	}
+}
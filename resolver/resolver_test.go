@@ -0,0 +1,164 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/parser"
+	"github.com/th-lange/glox/scanner"
+)
+
+func resolveSource(t *testing.T, source string) (*Resolver, []scanner.Token) {
+	scnr := scanner.Scanner{}
+	scnr.Scan(source)
+	assert.False(t, scnr.HadError, "Expecting the source to be scanned without errors: "+source)
+	statements := parser.NewParser(&scnr.Tokens).Parse()
+	assert.NotNil(t, statements, "Expecting the source to be parsed without errors: "+source)
+
+	rslvr := NewResolver()
+	rslvr.Resolve(statements)
+	return rslvr, scnr.Tokens
+}
+
+// findIdentifiers returns all identifier tokens with the given lexeme in order of appearance
+func findIdentifiers(tokens []scanner.Token, lexeme string) []scanner.Token {
+	found := make([]scanner.Token, 0, 4)
+	for _, tkn := range tokens {
+		if tkn.Type == scanner.IDENTIFIER && tkn.Lexeme == lexeme {
+			found = append(found, tkn)
+		}
+	}
+	return found
+}
+
+func TestResolver_Resolve_Depths(t *testing.T) {
+	rslvr, tokens := resolveSource(t, `var global = 1;
+print global;
+{
+  var a = 1;
+  print a;
+  {
+    print a;
+    a = 2;
+    fun f(param) {
+      print a;
+      print param;
+    }
+  }
+}`)
+	assert.False(t, rslvr.HadError, "Expecting no resolver errors.")
+
+	globals := findIdentifiers(tokens, "global")
+	_, ok := rslvr.Locals[globals[1]]
+	assert.False(t, ok, "Expecting globals not to be resolved.")
+
+	a := findIdentifiers(tokens, "a")
+	assert.Equal(t, 0, rslvr.Locals[a[1]], "Expecting a usage in the declaring block to have depth 0.")
+	assert.Equal(t, 1, rslvr.Locals[a[2]], "Expecting a usage in the nested block to have depth 1.")
+	assert.Equal(t, 1, rslvr.Locals[a[3]], "Expecting an assignment in the nested block to have depth 1.")
+	assert.Equal(t, 2, rslvr.Locals[a[4]], "Expecting a usage in the function to skip the function and the nested block.")
+
+	param := findIdentifiers(tokens, "param")
+	assert.Equal(t, 0, rslvr.Locals[param[1]], "Expecting parameters to live in the scope of the function body.")
+}
+
+func TestResolver_Resolve_Errors(t *testing.T) {
+	tests := []struct {
+		source  string
+		lexeme  string
+		message string
+	}{
+		{"{ var a = 1; { var a = a; } }", "a", "Can't read local variable in its own initializer."},
+		{"{ var a = 1; var a = 2; }", "a", "Already a variable with this name in this scope."},
+		{"fun f(a, a) {}", "a", "Already a variable with this name in this scope."},
+		{"return 1;", "return", "Can't return from top-level code."},
+		{"{ return; }", "return", "Can't return from top-level code."},
+		{"print this;", "this", "Can't use 'this' outside of a class."},
+		{"fun f() { return this; }", "this", "Can't use 'this' outside of a class."},
+		{"class Foo { init() { return 1; } }", "return", "Can't return a value from an initializer."},
+		{"super.foo();", "super", "Can't use 'super' outside of a class."},
+		{"class Foo { bar() { super.bar(); } }", "super", "Can't use 'super' in a class with no superclass."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			rslvr, _ := resolveSource(t, tt.source)
+			assert.True(t, rslvr.HadError, "Expecting a resolver error for: "+tt.source)
+			if assert.Len(t, rslvr.Errors, 1) {
+				err := rslvr.Errors[0].(ResolverError)
+				assert.Equal(t, tt.lexeme, err.Token.Lexeme)
+				assert.Equal(t, tt.message, err.Message)
+			}
+		})
+	}
+}
+
+func TestResolver_Resolve_ValidPrograms(t *testing.T) {
+	tests := []string{
+		"var a = 1; var a = a;",
+		"fun f() { return 1; }",
+		"fun f() { fun g() { return; } return g; }",
+		"{ var a = 1; { var a = 2; } }",
+		"class Foo { init() { return; } bar() { return this; } }",
+		"class Foo { bar() { fun baz() { return this; } return baz; } }",
+		"class A {} class B < A { bar() { fun baz() { return super.bar; } return baz; } }",
+		"{ class A {} class B < A { init() { super.init(); } } }",
+	}
+
+	for _, source := range tests {
+		t.Run(source, func(t *testing.T) {
+			rslvr, _ := resolveSource(t, source)
+			assert.False(t, rslvr.HadError, "Expecting no resolver error for: "+source)
+		})
+	}
+}
+
+func TestResolverError_Error(t *testing.T) {
+	err := ResolverError{
+		Token:   scanner.Token{Line: 2, Column: 1, Position: 7, Type: scanner.RETURN, Lexeme: "return"},
+		Message: "Can't return from top-level code.",
+	}
+	assert.Equal(t, "[Line 2] ResolverError at 'return' (Column 1): Can't return from top-level code.", err.Error())
+}
+
+func TestResolverError_Diagnostic(t *testing.T) {
+	tests := []struct {
+		source string
+		kind   ErrorKind
+		hint   string
+	}{
+		{"{ var a = a; }", READ_IN_OWN_INITIALIZER, "Use a different name, if a variable of an outer scope should be read."},
+		{"{ var a; var a; }", DUPLICATE_VARIABLE, "Assign the variable instead of declaring it again."},
+		{"class Foo { init() { return 1; } }", RETURN_VALUE_FROM_INITIALIZER, "An initializer always returns the instance, use 'return;' to leave it early."},
+		{"return;", INVALID_SEMANTICS, ""},
+	}
+
+	for _, tt := range tests {
+		rslvr, _ := resolveSource(t, tt.source)
+		if assert.Len(t, rslvr.Errors, 1, tt.source) {
+			err := rslvr.Errors[0].(ResolverError)
+			assert.Equal(t, tt.kind, err.Kind, tt.source)
+			assert.Equal(t, tt.hint, err.Diagnostic().Hint, tt.source)
+		}
+	}
+}
+
+func TestResolver_Resolve_This(t *testing.T) {
+	rslvr, tokens := resolveSource(t, `class Foo {
+  bar() {
+    fun baz() { return this; }
+    return this;
+  }
+}`)
+	assert.False(t, rslvr.HadError, "Expecting no resolver errors.")
+
+	this := make([]scanner.Token, 0, 2)
+	for _, tkn := range tokens {
+		if tkn.Type == scanner.THIS {
+			this = append(this, tkn)
+		}
+	}
+	assert.Equal(t, 2, rslvr.Locals[this[0]], "Expecting this in a nested function to skip the function and the method.")
+	assert.Equal(t, 1, rslvr.Locals[this[1]], "Expecting this in a method to skip the method scope.")
+}
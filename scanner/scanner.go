@@ -1,21 +1,44 @@
 package scanner
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
+// Scanner splits the source into tokens. Line and lineStart follow the current position,
+// lineStart is the offset of the first character of the line, the columns are counted from there.
+// interpolations holds the strings, whose embedded expressions are scanned at the moment.
+// With KeepComments, comments are emitted as COMMENT and DOC_COMMENT tokens for formatters and documentation tools,
+// the parser doesn't expect them.
+//
+// A stream scanner reads the source from reader on demand. lines is a window of the source then, starting at
+// offset. The positions inside of the scanner are relative to the window, tokens and errors get the offset added.
 type Scanner struct {
-	Errors   []error
-	Tokens   []Token
-	HadError bool
-	Debug    int8
-	Line     int
-	current  int
-	length   int
-	lines    string
+	Errors         []error
+	Tokens         []Token
+	HadError       bool
+	Debug          int8
+	KeepComments   bool
+	Line           int
+	current        int
+	lineStart      int
+	length         int
+	lines          string
+	interpolations []interpolation
+	reader         *bufio.Reader
+	offset         int
+	reported       int
+}
+
+// interpolation counts the open braces of an embedded expression, the '}' closing it continues the string
+type interpolation struct {
+	braces int
+	quote  Token
 }
 
 var simpleTokenTypes = map[rune]TokenType{
@@ -41,13 +64,17 @@ func (scnr *Scanner) Scan(lines string) {
 	scnr.Tokens = make([]Token, 0, 32)
 	scnr.current = 0
 	scnr.Line = 1
+	scnr.lineStart = 0
 	scnr.length = len(lines)
 	scnr.lines = lines
+	scnr.interpolations = nil
+	scnr.reader = nil
+	scnr.offset = 0
 
 	scnr.HadError = false
-	scnr.scanTokens(lines)
-
-	scnr.appendEOFToken()
+	for scnr.step() {
+	}
+	scnr.finish()
 
 	if scnr.Debug > 0 {
 		fmt.Println("Scanner Result:")
@@ -57,44 +84,187 @@ func (scnr *Scanner) Scan(lines string) {
 	}
 }
 
+// NewStreamScanner scans the source read from reader on demand, see Next. Only the rest of the current line
+// is kept in memory, strings and block comments spanning several lines up to their end.
+func NewStreamScanner(reader io.Reader) *Scanner {
+	return &Scanner{
+		Line:   1,
+		reader: bufio.NewReader(reader),
+	}
+}
+
+// Next scans the stream up to the next token. Errors are returned one at a time, the next call continues
+// after them. At the end of the stream, the EOF token is returned on every call.
+func (scnr *Scanner) Next() (Token, error) {
+	for {
+		if scnr.reported < len(scnr.Errors) {
+			scnr.reported += 1
+			return Token{}, scnr.Errors[scnr.reported-1]
+		}
+		if len(scnr.Tokens) > 0 {
+			tkn := scnr.Tokens[0]
+			if tkn.Type != EOF {
+				scnr.Tokens = scnr.Tokens[1:]
+			}
+			return tkn, nil
+		}
+		scnr.compact()
+		if !scnr.step() {
+			scnr.finish()
+		}
+	}
+}
+
+// Channel sends the tokens of the stream up to EOF, scanned in the background. Errors are not sent,
+// they can be read from Errors, after the channel is closed. The channel has to be read to its end.
+func (scnr *Scanner) Channel() <-chan Token {
+	tokens := make(chan Token, 64)
+	go func() {
+		defer close(tokens)
+		for {
+			tkn, err := scnr.Next()
+			if err != nil {
+				continue
+			}
+			tokens <- tkn
+			if tkn.Type == EOF {
+				return
+			}
+		}
+	}()
+	return tokens
+}
+
+// step scans the next token, it is false at the end of the source
+func (scnr *Scanner) step() bool {
+	if scnr.current >= scnr.length {
+		scnr.fill()
+	}
+	cur, peek := scnr.nextChars()
+	if cur == 0 {
+		return false
+	}
+	err := scnr.getNextToken(cur, peek)
+	if err != nil {
+		scnr.appendError(err)
+	}
+	return true
+}
+
+// finish reports the strings still waiting for the end of an embedded expression and appends the EOF token
+func (scnr *Scanner) finish() {
+	for _, open := range scnr.interpolations {
+		scnr.appendError(ScannerError{
+			Kind:     UNTERMINATED_INTERPOLATION,
+			Line:     open.quote.Line,
+			Column:   open.quote.Column,
+			Position: open.quote.Position,
+			Length:   1,
+			Message:  "Unterminated string interpolation",
+		})
+	}
+	scnr.interpolations = nil
+	scnr.appendEOFToken()
+}
+
+// fill appends the next line of the stream to the window. It is false at the end of the stream.
+// As whole lines are read, the window always ends with a '\n' or with the source, so tokens
+// ending on their line never have to wait for more input.
+func (scnr *Scanner) fill() bool {
+	if scnr.reader == nil {
+		return false
+	}
+	line, err := scnr.reader.ReadString('\n')
+	if err != nil {
+		if err != io.EOF {
+			scnr.appendError(err)
+		}
+		scnr.reader = nil
+	}
+	scnr.lines += line
+	scnr.length = len(scnr.lines)
+	return line != ""
+}
+
+// compact drops the lines before the current one from the window
+func (scnr *Scanner) compact() {
+	shift := scnr.lineStart
+	if shift == 0 {
+		return
+	}
+	scnr.lines = scnr.lines[shift:]
+	scnr.length -= shift
+	scnr.current -= shift
+	scnr.lineStart = 0
+	scnr.offset += shift
+	for i := range scnr.interpolations {
+		scnr.interpolations[i].quote.Position -= shift
+	}
+}
+
 func (scnr *Scanner) appendError(err error) {
+	if se, ok := err.(ScannerError); ok {
+		se.Position += scnr.offset
+		err = se
+	}
 	scnr.HadError = true
 	scnr.Errors = append(scnr.Errors, err)
 }
 
 func (scnr *Scanner) appendToken(tkn Token) {
+	tkn.Position += scnr.offset
 	scnr.Tokens = append(scnr.Tokens, tkn)
 }
 
-func (scnr *Scanner) scanTokens(line string) {
-	for {
-		cur, peek := scnr.nextChars()
-		if cur == 0 {
-			break
-		}
-		err := scnr.getNextToken(cur, peek)
-		if err == io.EOF {
-			return
-		} else if err != nil {
-			scnr.appendError(err)
-		}
+// newLine is called for every line break, offset is the position of the '\n'
+func (scnr *Scanner) newLine(offset int) {
+	scnr.Line += 1
+	scnr.lineStart = offset + 1
+}
+
+// column returns the 1-based column of the offset in the current line, counted in runes
+func (scnr *Scanner) column(offset int) int {
+	if offset < scnr.lineStart {
+		return 1
 	}
+	return utf8.RuneCountInString(scnr.lines[scnr.lineStart:offset]) + 1
 }
 
+// nextChars decodes the rune at the current position and the one following it.
+// Invalid encodings are returned as utf8.RuneError.
 func (scnr *Scanner) nextChars() (rune, rune) {
-	if scnr.current+1 < scnr.length {
-		return rune(scnr.lines[scnr.current]), rune(scnr.lines[scnr.current+1])
-	} else if scnr.current < scnr.length {
-		return rune(scnr.lines[scnr.current]), 0
+	if scnr.current >= scnr.length {
+		return 0, 0
 	}
-	return 0, 0
+	cur, width := utf8.DecodeRuneInString(scnr.lines[scnr.current:])
+	if scnr.current+width >= scnr.length {
+		return cur, 0
+	}
+	peek, _ := utf8.DecodeRuneInString(scnr.lines[scnr.current+width:])
+	return cur, peek
+}
 
+// invalidEncoding checks, if the bytes at offset are no valid UTF-8 sequence
+func (scnr *Scanner) invalidEncoding(offset int) bool {
+	chr, width := utf8.DecodeRuneInString(scnr.lines[offset:])
+	return chr == utf8.RuneError && width == 1
+}
+
+func (scnr *Scanner) encodingError(offset int) ScannerError {
+	return ScannerError{
+		Line:     scnr.Line,
+		Column:   scnr.column(offset),
+		Position: offset,
+		Length:   1,
+		Message:  fmt.Sprintf("Invalid UTF-8 encoding: 0x%02x", scnr.lines[offset]),
+	}
 }
 
 func (scnr *Scanner) getNextToken(cur, peek rune) error {
 	tkn := Token{
 		Position: scnr.current,
 		Line:     scnr.Line,
+		Column:   scnr.column(scnr.current),
 		Lexeme:   string(cur),
 		Length:   1,
 	}
@@ -104,10 +274,26 @@ func (scnr *Scanner) getNextToken(cur, peek rune) error {
 		scnr.current += 1
 		return nil
 	case '\n':
+		scnr.newLine(scnr.current)
 		scnr.current += 1
-		scnr.Line += 1
 		return nil
-	case '(', ')', '{', '}', ',', '.', '-', '+', ';', '*':
+	case '{':
+		if len(scnr.interpolations) > 0 {
+			scnr.interpolations[len(scnr.interpolations)-1].braces += 1
+		}
+		tkn.Type = LEFT_BRACE
+	case '}':
+		if open := len(scnr.interpolations) - 1; open >= 0 {
+			if scnr.interpolations[open].braces == 0 {
+				// the embedded expression ends, the string continues after the brace
+				quote := scnr.interpolations[open].quote
+				scnr.interpolations = scnr.interpolations[:open]
+				return scnr.string(&tkn, quote)
+			}
+			scnr.interpolations[open].braces -= 1
+		}
+		tkn.Type = RIGHT_BRACE
+	case '(', ')', ',', '.', '-', '+', ';', '*':
 		tkn.Type = simpleTokenTypes[cur]
 	case '!':
 		if peek == '=' {
@@ -149,31 +335,40 @@ func (scnr *Scanner) getNextToken(cur, peek rune) error {
 		}
 	case '/':
 		if peek == '/' {
-			// consume til eol / eof
-			// do not create lexeme
-			return scnr.consume('\n')
+			scnr.lineComment(&tkn)
+			return nil
 		} else if peek == '*' {
-			return scnr.consumeUntilTwo('*', '/')
+			return scnr.blockComment(&tkn)
 		} else {
 			tkn.Type = SLASH
 		}
 	case '"':
-		err := scnr.string(&tkn)
-		if err != nil {
-			return err
-		}
+		return scnr.string(&tkn, tkn)
 	default:
 		// Numbers
-		if unicode.IsDigit(cur) {
+		// number and identifier leave current on the first character after the token
+		if scnr.invalidEncoding(scnr.current) {
+			scnr.current += 1
+			return scnr.encodingError(tkn.Position)
+		} else if '0' <= cur && cur <= '9' {
 			err := scnr.number(&tkn)
 			if err != nil {
 				return err
 			}
+			scnr.appendToken(tkn)
+			return nil
 		} else if unicode.IsLetter(cur) {
 			scnr.identifier(&tkn)
+			scnr.appendToken(tkn)
+			return nil
 		} else {
+			// the character is skipped, so the scanning continues after it
+			scnr.current += utf8.RuneLen(cur)
 			return ScannerError{
-				Position: scnr.current,
+				Line:     tkn.Line,
+				Column:   tkn.Column,
+				Position: tkn.Position,
+				Length:   1,
 				Message:  "Unexpected character: " + string(cur),
 			}
 		}
@@ -191,81 +386,319 @@ func (scnr *Scanner) appendEOFToken() {
 		Lexeme:   "EOF",
 		Length:   0,
 		Line:     scnr.Line,
+		Column:   scnr.column(scnr.current),
 		Type:     EOF,
 	})
 }
 
+// consume skips runes until limiter, which has to be ASCII. Invalid encodings are reported on the way,
+// the skipped runes still belong to a comment.
 func (scnr *Scanner) consume(limiter rune) error {
-	for scnr.current < scnr.length-1 && scnr.lines[scnr.current] != uint8(limiter) {
+	for scnr.current < scnr.length && scnr.lines[scnr.current] != uint8(limiter) {
 		if scnr.lines[scnr.current] == '\n' {
-			scnr.Line += 1
+			scnr.newLine(scnr.current)
+		} else if scnr.invalidEncoding(scnr.current) {
+			scnr.appendError(scnr.encodingError(scnr.current))
 		}
-		scnr.current += 1
+		_, width := utf8.DecodeRuneInString(scnr.lines[scnr.current:])
+		scnr.current += width
 	}
 
-	if scnr.current == scnr.length-1 {
+	if scnr.current == scnr.length {
 		return io.EOF
 	}
 	return nil
 }
 
-func (scnr *Scanner) consumeUntilTwo(first, second rune) error {
-	err := scnr.consume(first)
+// lineComment skips the comment up to the end of the line, the '\n' is scanned afterwards
+func (scnr *Scanner) lineComment(tkn *Token) {
+	scnr.consume('\n')
+	scnr.appendComment(tkn, "///", "")
+}
 
-	if scnr.current+1 < scnr.length-1 && scnr.lines[scnr.current+1] != uint8(second) {
-		scnr.current += 1
-		return scnr.consumeUntilTwo(first, second)
+// blockComment skips the comment starting at the current "/*". Block comments nest, so every "/*"
+// has to be closed with its own "*/". Unterminated comments are reported at their opening.
+func (scnr *Scanner) blockComment(tkn *Token) error {
+	depth := 0
+	for scnr.current < scnr.length || scnr.fill() {
+		if strings.HasPrefix(scnr.lines[scnr.current:], "/*") {
+			depth += 1
+			scnr.current += 2
+			continue
+		}
+		if strings.HasPrefix(scnr.lines[scnr.current:], "*/") {
+			depth -= 1
+			scnr.current += 2
+			if depth == 0 {
+				scnr.appendComment(tkn, "/**", "*/")
+				return nil
+			}
+			continue
+		}
+		if scnr.lines[scnr.current] == '\n' {
+			scnr.newLine(scnr.current)
+		} else if scnr.invalidEncoding(scnr.current) {
+			scnr.appendError(scnr.encodingError(scnr.current))
+		}
+		_, width := utf8.DecodeRuneInString(scnr.lines[scnr.current:])
+		scnr.current += width
 	}
 
-	if scnr.current+2 < scnr.length-1 {
-		scnr.current += 2
+	return ScannerError{
+		Kind:     UNTERMINATED_COMMENT,
+		Line:     tkn.Line,
+		Column:   tkn.Column,
+		Position: tkn.Position,
+		Length:   2,
+		Message:  "Unterminated block comment",
 	}
-	return err
 }
 
-func (scnr *Scanner) string(tkn *Token) error {
+// appendComment appends the comment up to the current position, if the comments are kept.
+// Comments opened with doc, but not with more slashes or stars, are DOC_COMMENTs. So "/**/", "/***"
+// and "////" are plain comments. The Literal is the text between the delimiters.
+func (scnr *Scanner) appendComment(tkn *Token, doc string, closing string) {
+	if !scnr.KeepComments {
+		return
+	}
+	tkn.Lexeme = scnr.lines[tkn.Position:scnr.current]
+	tkn.Length = len(tkn.Lexeme)
+	text := strings.TrimSuffix(tkn.Lexeme, closing)
+
+	tkn.Type = COMMENT
+	tkn.Literal = text[2:]
+	if strings.HasPrefix(text, doc) && !strings.HasPrefix(text[len(doc):], doc[len(doc)-1:]) {
+		tkn.Type = DOC_COMMENT
+		tkn.Literal = text[len(doc):]
+	}
+	scnr.appendToken(*tkn)
+}
+
+// string scans a string segment, starting on the opening '"' or on the '}' ending an embedded expression.
+// The segment ends with the closing '"' as STRING or with "${" as INTERPOLATION. The Lexeme is the source
+// of the segment, the Literal has the escape sequences decoded. quote is the start of the whole string.
+func (scnr *Scanner) string(tkn *Token, quote Token) error {
 	// we will allow multi line strings
 	scnr.current += 1
-	err := scnr.consume('"')
-	if err == io.EOF && scnr.lines[scnr.current] != '"' {
-		return ScannerError{
-			Line:     scnr.Line,
-			Position: scnr.current,
-			Message:  "Unterminated string",
+	tkn.Position = scnr.current
+	tkn.Column += 1
+
+	literal := strings.Builder{}
+	for scnr.current < scnr.length || scnr.fill() {
+		chr, width := utf8.DecodeRuneInString(scnr.lines[scnr.current:])
+		switch {
+		case chr == '"':
+			tkn.Type = STRING
+			scnr.appendSegment(tkn, literal.String())
+			scnr.current += 1
+			return nil
+		case chr == '$' && scnr.current+1 < scnr.length && scnr.lines[scnr.current+1] == '{':
+			tkn.Type = INTERPOLATION
+			scnr.appendSegment(tkn, literal.String())
+			scnr.interpolations = append(scnr.interpolations, interpolation{quote: quote})
+			scnr.current += 2
+			return nil
+		case chr == '\\':
+			scnr.escape(&literal)
+			continue
+		case chr == '\n':
+			scnr.newLine(scnr.current)
+		case chr == utf8.RuneError && width == 1:
+			scnr.appendError(scnr.encodingError(scnr.current))
 		}
+		literal.WriteString(scnr.lines[scnr.current : scnr.current+width])
+		scnr.current += width
 	}
-	tkn.Type = STRING
-	tkn.Position += 1 // Remove leading "
+
+	return ScannerError{
+		Kind:     UNTERMINATED_STRING,
+		Line:     quote.Line,
+		Column:   quote.Column,
+		Position: quote.Position,
+		Length:   1,
+		Message:  "Unterminated string",
+	}
+}
+
+func (scnr *Scanner) appendSegment(tkn *Token, literal string) {
 	tkn.Length = scnr.current - tkn.Position
-	tkn.Literal = scnr.lines[tkn.Position : tkn.Position+tkn.Length]
-	tkn.Lexeme = scnr.lines[tkn.Position : tkn.Position+tkn.Length]
-	return nil
+	tkn.Lexeme = scnr.lines[tkn.Position:scnr.current]
+	tkn.Literal = literal
+	scnr.appendToken(*tkn)
+}
+
+var simpleEscapes = map[byte]rune{
+	'n':  '\n',
+	't':  '\t',
+	'"':  '"',
+	'\\': '\\',
+	'$':  '$',
+}
+
+// escape decodes the escape sequence at the current '\\' into the literal and moves behind it.
+// Unknown sequences are reported and left out of the literal, the string is scanned on.
+func (scnr *Scanner) escape(literal *strings.Builder) {
+	start := scnr.current
+	scnr.current += 1
+	if scnr.current >= scnr.length {
+		return
+	}
+
+	next := scnr.lines[scnr.current]
+	if chr, ok := simpleEscapes[next]; ok {
+		literal.WriteRune(chr)
+		scnr.current += 1
+		return
+	}
+	if next != 'u' {
+		if next != '\n' {
+			_, width := utf8.DecodeRuneInString(scnr.lines[scnr.current:])
+			scnr.current += width
+		}
+		scnr.appendError(scnr.escapeError(start, "Unknown escape sequence: "+scnr.lines[start:scnr.current]))
+		return
+	}
+
+	// \u{XXXXXX}, the code point has up to six hex digits
+	scnr.current += 1
+	if scnr.current >= scnr.length || scnr.lines[scnr.current] != '{' {
+		scnr.appendError(scnr.escapeError(start, "Invalid unicode escape sequence: "+scnr.lines[start:scnr.current]))
+		return
+	}
+	end := strings.IndexAny(scnr.lines[scnr.current:], "}\"\n")
+	if end < 0 || scnr.lines[scnr.current+end] != '}' {
+		scnr.appendError(scnr.escapeError(start, "Unterminated unicode escape sequence"))
+		return
+	}
+	digits := scnr.lines[scnr.current+1 : scnr.current+end]
+	scnr.current += end + 1
+	codePoint, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(codePoint)) {
+		scnr.appendError(scnr.escapeError(start, "Invalid unicode escape sequence: "+scnr.lines[start:scnr.current]))
+		return
+	}
+	literal.WriteRune(rune(codePoint))
+}
+
+func (scnr *Scanner) escapeError(start int, message string) ScannerError {
+	return ScannerError{
+		Line:     scnr.Line,
+		Column:   scnr.column(start),
+		Position: start,
+		Length:   scnr.current - start,
+		Message:  message,
+	}
 }
 
-func (scnr *Scanner) number(tkn *Token) (err error) {
+// number scans decimals with an optional fraction and exponent, 0x hex and 0b binary integers.
+// Digits may be separated by single '_'. The '.' only belongs to the number, if a digit follows it,
+// so 123.foo is a number, a dot and an identifier.
+func (scnr *Scanner) number(tkn *Token) error {
 	start := scnr.current
-	iterate := func() {
-		for scnr.current < scnr.length && unicode.IsDigit(rune(scnr.lines[scnr.current])) {
+	base := 10
+	if scnr.lines[start] == '0' && start+1 < scnr.length {
+		switch scnr.lines[start+1] {
+		case 'x', 'X':
+			base = 16
+		case 'b', 'B':
+			base = 2
+		}
+	}
+
+	valid := true
+	if base != 10 {
+		scnr.current += 2
+		valid = scnr.digits(base)
+	} else {
+		valid = scnr.digits(10)
+		if scnr.current+1 < scnr.length && scnr.lines[scnr.current] == '.' && isDigit(scnr.lines[scnr.current+1], 10) {
 			scnr.current += 1
+			valid = scnr.digits(10) && valid
+		}
+		if scnr.current < scnr.length && (scnr.lines[scnr.current] == 'e' || scnr.lines[scnr.current] == 'E') {
+			exponent := scnr.current + 1
+			if exponent < scnr.length && (scnr.lines[exponent] == '+' || scnr.lines[exponent] == '-') {
+				exponent += 1
+			}
+			if exponent < scnr.length && isDigit(scnr.lines[exponent], 10) {
+				scnr.current = exponent
+				valid = scnr.digits(10) && valid
+			}
 		}
 	}
-	iterate()
-	if scnr.current < scnr.length && scnr.lines[scnr.current] == uint8('.') {
-		scnr.current += 1
-		iterate()
+
+	// letters and digits directly after the number belong to it, e.g. 12abc or 0b102
+	for scnr.current < scnr.length {
+		chr, width := utf8.DecodeRuneInString(scnr.lines[scnr.current:])
+		if !unicode.IsLetter(chr) && !unicode.IsDigit(chr) && chr != '_' {
+			break
+		}
+		valid = false
+		scnr.current += width
 	}
+
 	tkn.Type = NUMBER
 	tkn.Length = scnr.current - start
 	tkn.Lexeme = scnr.lines[start:scnr.current]
-	tkn.Literal, err = strconv.ParseFloat(tkn.Lexeme, 64)
-	return err
+	if !valid {
+		return numberError(tkn, "Malformed number: "+tkn.Lexeme)
+	}
+
+	var err error
+	digits := strings.Replace(tkn.Lexeme, "_", "", -1)
+	if base == 10 {
+		tkn.Literal, err = strconv.ParseFloat(digits, 64)
+	} else {
+		var value uint64
+		value, err = strconv.ParseUint(digits[2:], base, 64)
+		tkn.Literal = float64(value)
+	}
+	if err != nil {
+		return numberError(tkn, "Number out of range: "+tkn.Lexeme)
+	}
+	return nil
+}
+
+// digits consumes the digits of base and their separators. It is false, if there are no digits
+// or a '_' doesn't stand between two digits.
+func (scnr *Scanner) digits(base int) bool {
+	start := scnr.current
+	for scnr.current < scnr.length && (isDigit(scnr.lines[scnr.current], base) || scnr.lines[scnr.current] == '_') {
+		scnr.current += 1
+	}
+	digits := scnr.lines[start:scnr.current]
+	return digits != "" && digits[0] != '_' && digits[len(digits)-1] != '_' && !strings.Contains(digits, "__")
+}
+
+func isDigit(chr byte, base int) bool {
+	switch base {
+	case 2:
+		return chr == '0' || chr == '1'
+	case 16:
+		return '0' <= chr && chr <= '9' || 'a' <= chr && chr <= 'f' || 'A' <= chr && chr <= 'F'
+	}
+	return '0' <= chr && chr <= '9'
+}
+
+func numberError(tkn *Token, message string) ScannerError {
+	return ScannerError{
+		Line:     tkn.Line,
+		Column:   tkn.Column,
+		Position: tkn.Position,
+		Length:   tkn.Length,
+		Message:  message,
+	}
 }
 
 func (scnr *Scanner) identifier(tkn *Token) {
 	start := scnr.current
 
-	for scnr.current < scnr.length && (isAlphaNumeric(rune(scnr.lines[scnr.current]))) {
-		scnr.current += 1
+	for scnr.current < scnr.length {
+		chr, width := utf8.DecodeRuneInString(scnr.lines[scnr.current:])
+		if !isAlphaNumeric(chr) {
+			break
+		}
+		scnr.current += width
 	}
 
 	tkn.Type = IDENTIFIER
@@ -2,14 +2,47 @@ package scanner
 
 import (
 	"strconv"
+
+	"github.com/th-lange/glox/diagnostic"
+)
+
+// ErrorKind identifies a ScannerError independent of its message, errors without a hint keep the zero kind
+type ErrorKind int
+
+const (
+	INVALID_SOURCE ErrorKind = iota
+	UNTERMINATED_STRING
+	UNTERMINATED_INTERPOLATION
+	UNTERMINATED_COMMENT
 )
 
+// Indicates that the SCANNED code contains characters, which don't form a token
 type ScannerError struct {
+	Kind     ErrorKind
 	Line     int
+	Column   int
 	Position int
+	Length   int
 	Message  string
 }
 
 func (se ScannerError) Error() string {
-	return "[Line " + strconv.Itoa(se.Line) + "] HadError" + strconv.Itoa(se.Position) + ": " + se.Message
+	return "[Line " + strconv.Itoa(se.Line) + "] ScannerError (Column " + strconv.Itoa(se.Column) + "): " + se.Message
+}
+
+var scannerHints = map[ErrorKind]string{
+	UNTERMINATED_STRING:        "Strings may span multiple lines, but have to be closed with '\"'.",
+	UNTERMINATED_INTERPOLATION: "Every '${' in a string has to be closed with '}'.",
+	UNTERMINATED_COMMENT:       "Block comments nest, every '/*' has to be closed with its own '*/'.",
+}
+
+func (se ScannerError) Diagnostic() diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{
+		Code:    diagnostic.SCANNER_ERROR,
+		Message: se.Message,
+		Line:    se.Line,
+		Offset:  se.Position,
+		Length:  se.Length,
+		Hint:    scannerHints[se.Kind],
+	}
 }
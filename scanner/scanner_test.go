@@ -2,7 +2,9 @@ package scanner
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -60,38 +62,38 @@ var expected = []Token{
 	{Line: 11, Type: LESS_EQUAL, Lexeme: "<="},
 	{Line: 11, Type: EQUAL_EQUAL, Lexeme: "=="},
 	{Line: 12, Type: NUMBER, Lexeme: "123", Literal: 123},
-	{Line: 12, Type: NUMBER, Lexeme: "1225", Literal: 1225},
-	{Line: 12, Type: NUMBER, Lexeme: "12.356", Literal: 12.356},
-	{Line: 12, Type: IDENTIFIER, Lexeme: "identifier"},
-	{Line: 12, Type: EQUAL, Lexeme: "="},
-	{Line: 12, Type: STRING, Lexeme: "Fooo", Literal: "Fooo"},
-	{Line: 13, Type: CLASS, Lexeme: "class"},
-	{Line: 13, Type: IDENTIFIER, Lexeme: "StrangeName"},
-	{Line: 13, Type: LEFT_BRACE, Lexeme: "{"},
-	{Line: 14, Type: VAR, Lexeme: "var"},
-	{Line: 14, Type: IDENTIFIER, Lexeme: "first"},
-	{Line: 14, Type: EQUAL, Lexeme: "="},
-	{Line: 14, Type: NUMBER, Lexeme: "123", Literal: 123},
-	{Line: 14, Type: RIGHT_BRACE, Lexeme: "}"},
-	{Line: 16, Type: IDENTIFIER, Lexeme: "iden_ti_fier"},
-	{Line: 16, Type: EQUAL, Lexeme: "="},
-	{Line: 16, Type: NUMBER, Lexeme: "123", Literal: 123},
-	{Line: 16, Type: NUMBER, Lexeme: "0123.1223", Literal: 123.1223},
-	{Line: 16, Type: STRING, Lexeme: `This is a very long string
+	{Line: 13, Type: NUMBER, Lexeme: "1225", Literal: 1225},
+	{Line: 14, Type: NUMBER, Lexeme: "12.356", Literal: 12.356},
+	{Line: 15, Type: IDENTIFIER, Lexeme: "identifier"},
+	{Line: 15, Type: EQUAL, Lexeme: "="},
+	{Line: 15, Type: STRING, Lexeme: "Fooo", Literal: "Fooo"},
+	{Line: 16, Type: CLASS, Lexeme: "class"},
+	{Line: 16, Type: IDENTIFIER, Lexeme: "StrangeName"},
+	{Line: 16, Type: LEFT_BRACE, Lexeme: "{"},
+	{Line: 17, Type: VAR, Lexeme: "var"},
+	{Line: 17, Type: IDENTIFIER, Lexeme: "first"},
+	{Line: 17, Type: EQUAL, Lexeme: "="},
+	{Line: 17, Type: NUMBER, Lexeme: "123", Literal: 123},
+	{Line: 18, Type: RIGHT_BRACE, Lexeme: "}"},
+	{Line: 20, Type: IDENTIFIER, Lexeme: "iden_ti_fier"},
+	{Line: 20, Type: EQUAL, Lexeme: "="},
+	{Line: 20, Type: NUMBER, Lexeme: "123", Literal: 123},
+	{Line: 21, Type: NUMBER, Lexeme: "0123.1223", Literal: 123.1223},
+	{Line: 22, Type: STRING, Lexeme: `This is a very long string
 that spans multiple lines
 
 KK`},
-	{Line: 20, Type: AND, Lexeme: "and"},
-	{Line: 20, Type: IDENTIFIER, Lexeme: "and_and"},
-	{Line: 20, Type: CLASS, Lexeme: "class"},
-	{Line: 20, Type: IDENTIFIER, Lexeme: "class_class"},
-	{Line: 20, Type: ELSE, Lexeme: "else"},
-	{Line: 20, Type: IDENTIFIER, Lexeme: "else_else"},
-	{Line: 20, Type: FALSE, Lexeme: "false"},
-	{Line: 20, Type: IDENTIFIER, Lexeme: "false_false"},
-	{Line: 20, Type: FOR, Lexeme: "for"},
-	{Line: 20, Type: IDENTIFIER, Lexeme: "for_for"},
-	{Line: 22, Type: EOF, Lexeme: "EOF"},
+	{Line: 26, Type: AND, Lexeme: "and"},
+	{Line: 26, Type: IDENTIFIER, Lexeme: "and_and"},
+	{Line: 27, Type: CLASS, Lexeme: "class"},
+	{Line: 27, Type: IDENTIFIER, Lexeme: "class_class"},
+	{Line: 28, Type: ELSE, Lexeme: "else"},
+	{Line: 28, Type: IDENTIFIER, Lexeme: "else_else"},
+	{Line: 29, Type: FALSE, Lexeme: "false"},
+	{Line: 29, Type: IDENTIFIER, Lexeme: "false_false"},
+	{Line: 30, Type: FOR, Lexeme: "for"},
+	{Line: 30, Type: IDENTIFIER, Lexeme: "for_for"},
+	{Line: 34, Type: EOF, Lexeme: "EOF"},
 }
 
 func TestScanner_Scan(t *testing.T) {
@@ -183,6 +185,7 @@ func TestScanner_appendEOFToken(t *testing.T) {
 		Lexeme:   "EOF",
 		Length:   0,
 		Line:     scnr.Line,
+		Column:   1,
 		Type:     EOF,
 	}
 	assert.Contains(t, scnr.Tokens, expected, "Expects, that an EOF Token is appended.")
@@ -207,20 +210,18 @@ func TestScanner_consume(t *testing.T) {
 	assert.Equal(t, 25, scnr.current, "Expecting Scanner Consume to consume all characters up to the /")
 }
 
-func TestScanner_consumeUntilTwo(t *testing.T) {
-	line := "This it // a test /* line*/"
+func TestScanner_blockComment(t *testing.T) {
+	line := "x /* a /* nested */ comment */ y"
 	scnr := Scanner{
 		lines:   line,
 		Line:    1,
-		current: 0,
+		current: 2,
 		length:  len(line),
 	}
 
-	scnr.consumeUntilTwo('/', '/')
-	assert.Equal(t, 10, scnr.current, "Expecting Scanner Consume to consume all characters up to the //")
-
-	scnr.consumeUntilTwo('/', '*')
-	assert.Equal(t, 20, scnr.current, "Expecting Scanner Consume to consume all characters up to the /*")
+	err := scnr.blockComment(&Token{Position: 2, Line: 1, Column: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 30, scnr.current, "Expecting the nested comment to be skipped as a whole.")
 }
 
 func TestScanner_string_ok(t *testing.T) {
@@ -238,7 +239,7 @@ func TestScanner_string_ok(t *testing.T) {
 		Length:   1,
 	}
 
-	err := scnr.string(&tkn)
+	err := scnr.string(&tkn, tkn)
 	assert.NoError(t, err, "Expecting no error after parsing strings.")
 	assert.Equal(t, STRING, tkn.Type, "Expected TokenType to be string after string parsing.")
 	assert.Equal(t, "This is a super string", tkn.Lexeme, "Expecting the correct string to be parsed.")
@@ -259,7 +260,7 @@ func TestScanner_string_error(t *testing.T) {
 		Length:   1,
 	}
 
-	err := scnr.string(&tkn)
+	err := scnr.string(&tkn, tkn)
 	assert.Error(t, err, "Expecting no error after parsing strings.")
 }
 
@@ -337,3 +338,438 @@ func TestScanner_isAlphaNumeric(t *testing.T) {
 	assert.False(t, isAlphaNumeric('>'), "Expecting < not to be considered alphaNumeric")
 	assert.False(t, isAlphaNumeric('<'), "Expecting > not to be considered alphaNumeric")
 }
+
+func TestScanner_Scan_NoSkipAfterLiteral(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan("(12)*abc;")
+
+	expected := []TokenType{LEFT_PAREN, NUMBER, RIGHT_PAREN, STAR, IDENTIFIER, SEMICOLON, EOF}
+	assert.Equal(t, len(expected), len(scnr.Tokens), "Expecting no character to be skipped after numbers and identifiers.")
+	for i, tokenType := range expected {
+		assert.Equal(t, tokenType, scnr.Tokens[i].Type)
+	}
+}
+
+func TestScanner_Scan_UnexpectedCharacter(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan("a @ b\n#")
+
+	assert.True(t, scnr.HadError, "Expecting HadErrors to be set")
+	assert.Equal(t, []error{
+		ScannerError{Line: 1, Column: 3, Position: 2, Length: 1, Message: "Unexpected character: @"},
+		ScannerError{Line: 2, Column: 1, Position: 6, Length: 1, Message: "Unexpected character: #"},
+	}, scnr.Errors, "Expecting the scanning to continue after an unexpected character.")
+	assert.Len(t, scnr.Tokens, 3, "Expecting both identifiers and EOF.")
+	assert.EqualError(t, scnr.Errors[0], "[Line 1] ScannerError (Column 3): Unexpected character: @")
+}
+
+func TestScanner_Scan_Columns(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		line   int
+		column int
+	}{
+		{"first token", "x", 1, 1},
+		{"after spaces", "var abc = 12;", 1, 13},
+		{"after multi line string", "\"a\nbc\" x", 2, 5},
+		{"after block comment", "/* a\n b */ x", 2, 7},
+		{"after line comment", "// a\n  x", 2, 3},
+		{"columns count runes", "\"äö\" x", 1, 6},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+		assert.False(t, scnr.HadError, tt.name)
+		last := scnr.Tokens[len(scnr.Tokens)-2]
+		assert.Equal(t, tt.line, last.Line, tt.name)
+		assert.Equal(t, tt.column, last.Column, tt.name)
+	}
+}
+
+func TestScanner_Scan_StringColumn(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan("x = \"abc\";")
+
+	assert.Equal(t, 6, scnr.Tokens[2].Column, "Expecting the column of a string to start after the quote.")
+}
+
+func TestScanner_Scan_UnterminatedStringColumn(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan("print 1;\n  x = \"abc")
+
+	assert.Equal(t, []error{
+		ScannerError{Kind: UNTERMINATED_STRING, Line: 2, Column: 7, Position: 15, Length: 1, Message: "Unterminated string"},
+	}, scnr.Errors, "Expecting the error at the opening quote.")
+}
+
+func TestScannerError_Diagnostic(t *testing.T) {
+	tests := []struct {
+		source string
+		hint   string
+	}{
+		{"\"abc", "Strings may span multiple lines, but have to be closed with '\"'."},
+		{"\"a${1", "Every '${' in a string has to be closed with '}'."},
+		{"/* /* */", "Block comments nest, every '/*' has to be closed with its own '*/'."},
+		{"@", ""},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+		if assert.Len(t, scnr.Errors, 1, tt.source) {
+			assert.Equal(t, tt.hint, scnr.Errors[0].(ScannerError).Diagnostic().Hint, tt.source)
+		}
+	}
+}
+
+func TestScanner_Scan_Unicode(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan("var größe = \"héllo €\"; π;")
+
+	assert.False(t, scnr.HadError, "Expecting unicode to be valid source.")
+	assert.Equal(t, IDENTIFIER, scnr.Tokens[1].Type)
+	assert.Equal(t, "größe", scnr.Tokens[1].Lexeme, "Expecting unicode letters to be part of identifiers.")
+	assert.Equal(t, len("größe"), scnr.Tokens[1].Length, "Expecting the length in bytes.")
+	assert.Equal(t, "héllo €", scnr.Tokens[3].Literal, "Expecting multi byte characters to be kept in strings.")
+	assert.Equal(t, "π", scnr.Tokens[5].Lexeme)
+	assert.Equal(t, 24, scnr.Tokens[5].Column)
+}
+
+func TestScanner_Scan_UnexpectedUnicodeCharacter(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan("a € b")
+
+	assert.Equal(t, []error{
+		ScannerError{Line: 1, Column: 3, Position: 2, Length: 1, Message: "Unexpected character: €"},
+	}, scnr.Errors, "Expecting the whole rune to be reported.")
+	assert.Equal(t, "b", scnr.Tokens[1].Lexeme, "Expecting the scanning to continue after the rune.")
+}
+
+func TestScanner_Scan_InvalidEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected ScannerError
+	}{
+		{"in code", "a \xff b", ScannerError{Line: 1, Column: 3, Position: 2, Length: 1, Message: "Invalid UTF-8 encoding: 0xff"}},
+		{"in identifier", "ab\xc3", ScannerError{Line: 1, Column: 3, Position: 2, Length: 1, Message: "Invalid UTF-8 encoding: 0xc3"}},
+		{"in string", "\"a\n\xe2\x82\"; x", ScannerError{Line: 2, Column: 1, Position: 3, Length: 1, Message: "Invalid UTF-8 encoding: 0xe2"}},
+		{"in comment", "// \xc0\nx", ScannerError{Line: 1, Column: 4, Position: 3, Length: 1, Message: "Invalid UTF-8 encoding: 0xc0"}},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+		assert.True(t, scnr.HadError, tt.name)
+		assert.Contains(t, scnr.Errors, tt.expected, tt.name)
+		assert.Equal(t, EOF, scnr.Tokens[len(scnr.Tokens)-1].Type, tt.name)
+	}
+}
+
+func TestScanner_Scan_Escapes(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{`"a\nb"`, "a\nb"},
+		{`"a\tb"`, "a\tb"},
+		{`"say \"hi\""`, "say \"hi\""},
+		{`"back\\slash"`, "back\\slash"},
+		{`"\${not embedded}"`, "${not embedded}"},
+		{`"\u{e4}\u{1F600}"`, "ä😀"},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+		assert.False(t, scnr.HadError, tt.source)
+		assert.Equal(t, STRING, scnr.Tokens[0].Type, tt.source)
+		assert.Equal(t, tt.expected, scnr.Tokens[0].Literal, "Expecting the escapes to be decoded in the literal: "+tt.source)
+		assert.Equal(t, tt.source[1:len(tt.source)-1], scnr.Tokens[0].Lexeme, "Expecting the lexeme to be the source: "+tt.source)
+	}
+}
+
+func TestScanner_Scan_InvalidEscapes(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected ScannerError
+	}{
+		{`"a\qb"`, ScannerError{Line: 1, Column: 3, Position: 2, Length: 2, Message: "Unknown escape sequence: \\q"}},
+		{`"\u41"`, ScannerError{Line: 1, Column: 2, Position: 1, Length: 2, Message: "Invalid unicode escape sequence: \\u"}},
+		{`"\u{}"`, ScannerError{Line: 1, Column: 2, Position: 1, Length: 4, Message: "Invalid unicode escape sequence: \\u{}"}},
+		{`"\u{zz}"`, ScannerError{Line: 1, Column: 2, Position: 1, Length: 6, Message: "Invalid unicode escape sequence: \\u{zz}"}},
+		{`"\u{D800}"`, ScannerError{Line: 1, Column: 2, Position: 1, Length: 8, Message: "Invalid unicode escape sequence: \\u{D800}"}},
+		{`"\u{110000}"`, ScannerError{Line: 1, Column: 2, Position: 1, Length: 10, Message: "Invalid unicode escape sequence: \\u{110000}"}},
+		{`"\u{41"`, ScannerError{Line: 1, Column: 2, Position: 1, Length: 2, Message: "Unterminated unicode escape sequence"}},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+		assert.Equal(t, []error{tt.expected}, scnr.Errors, tt.source)
+		assert.Equal(t, STRING, scnr.Tokens[0].Type, "Expecting the string to be scanned on: "+tt.source)
+	}
+}
+
+func TestScanner_Scan_Interpolation(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan(`"Hello ${name}, ${ {"${1}"} }!"`)
+
+	assert.False(t, scnr.HadError)
+	expected := []struct {
+		tokenType TokenType
+		lexeme    string
+	}{
+		{INTERPOLATION, "Hello "},
+		{IDENTIFIER, "name"},
+		{INTERPOLATION, ", "},
+		{LEFT_BRACE, "{"},
+		{INTERPOLATION, ""},
+		{NUMBER, "1"},
+		{STRING, ""},
+		{RIGHT_BRACE, "}"},
+		{STRING, "!"},
+		{EOF, "EOF"},
+	}
+	assert.Len(t, scnr.Tokens, len(expected))
+	for i, tkn := range expected {
+		assert.Equal(t, tkn.tokenType, scnr.Tokens[i].Type, "Token %d", i)
+		assert.Equal(t, tkn.lexeme, scnr.Tokens[i].Lexeme, "Token %d", i)
+	}
+	assert.Equal(t, 2, scnr.Tokens[0].Column)
+	assert.Equal(t, 15, scnr.Tokens[2].Column, "Expecting the segment to start after the '}'.")
+}
+
+func TestScanner_Scan_UnterminatedInterpolation(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan("x = \"a ${b")
+
+	assert.Equal(t, []error{
+		ScannerError{Kind: UNTERMINATED_INTERPOLATION, Line: 1, Column: 5, Position: 4, Length: 1, Message: "Unterminated string interpolation"},
+	}, scnr.Errors, "Expecting the error at the opening quote.")
+}
+
+func TestScanner_Scan_Numbers(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected float64
+	}{
+		{"0", 0},
+		{"12.5", 12.5},
+		{"0x1F", 31},
+		{"0XfF", 255},
+		{"0b1010", 10},
+		{"1e3", 1000},
+		{"1e-9", 1e-9},
+		{"2.5E+2", 250},
+		{"1_000_000", 1000000},
+		{"0xFF_FF", 65535},
+		{"0b1111_0000", 240},
+		{"1_0.0_1e1_0", 10.01e10},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+		assert.False(t, scnr.HadError, tt.source)
+		assert.Len(t, scnr.Tokens, 2, tt.source)
+		assert.Equal(t, NUMBER, scnr.Tokens[0].Type, tt.source)
+		assert.Equal(t, tt.source, scnr.Tokens[0].Lexeme, "Expecting the lexeme to be the source: "+tt.source)
+		assert.Equal(t, tt.expected, scnr.Tokens[0].Literal, tt.source)
+	}
+}
+
+func TestScanner_Scan_MalformedNumbers(t *testing.T) {
+	tests := []struct {
+		source string
+		lexeme string
+	}{
+		{"12abc;", "12abc"},
+		{"0x;", "0x"},
+		{"0xG1;", "0xG1"},
+		{"0b102;", "0b102"},
+		{"1e;", "1e"},
+		{"1e+;", "1e"},
+		{"1__0;", "1__0"},
+		{"1_;", "1_"},
+		{"1_.5;", "1_.5"},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+		assert.Contains(t, scnr.Errors, ScannerError{Line: 1, Column: 1, Position: 0, Length: len(tt.lexeme), Message: "Malformed number: " + tt.lexeme}, tt.source)
+		assert.Equal(t, SEMICOLON, scnr.Tokens[len(scnr.Tokens)-2].Type, "Expecting the scanning to continue after the number: "+tt.source)
+	}
+}
+
+func TestScanner_Scan_NumberOutOfRange(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan("1e999 0x1_0000_0000_0000_0000")
+
+	assert.Equal(t, []error{
+		ScannerError{Line: 1, Column: 1, Position: 0, Length: 5, Message: "Number out of range: 1e999"},
+		ScannerError{Line: 1, Column: 7, Position: 6, Length: 23, Message: "Number out of range: 0x1_0000_0000_0000_0000"},
+	}, scnr.Errors)
+}
+
+func TestScanner_Scan_NumberFollowedByDot(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected []TokenType
+	}{
+		{"123.foo", []TokenType{NUMBER, DOT, IDENTIFIER, EOF}},
+		{"12.", []TokenType{NUMBER, DOT, EOF}},
+		{"1.5.2", []TokenType{NUMBER, DOT, NUMBER, EOF}},
+		{"0x1F.a", []TokenType{NUMBER, DOT, IDENTIFIER, EOF}},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+		assert.False(t, scnr.HadError, tt.source)
+		types := []TokenType{}
+		for _, tkn := range scnr.Tokens {
+			types = append(types, tkn.Type)
+		}
+		assert.Equal(t, tt.expected, types, tt.source)
+	}
+}
+
+func TestScanner_Scan_Comments(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"line comment", "// a\nx"},
+		{"line comment at end of file", "x // a"},
+		{"block comment", "/* a */ x"},
+		{"block comment at end of file", "x /* a */"},
+		{"nested block comments", "/* a /* b */ c */ x"},
+		{"empty block comment", "/**/x"},
+		{"stars in block comment", "/*** a * b ***/ x"},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+		assert.False(t, scnr.HadError, tt.name)
+		assert.Len(t, scnr.Tokens, 2, "Expecting the comment to be skipped: "+tt.name)
+		assert.Equal(t, "x", scnr.Tokens[0].Lexeme, tt.name)
+	}
+}
+
+func TestScanner_Scan_UnterminatedBlockComment(t *testing.T) {
+	scnr := Scanner{}
+	scnr.Scan("x\n  /* a /* b */\n\n")
+
+	assert.Equal(t, []error{
+		ScannerError{Kind: UNTERMINATED_COMMENT, Line: 2, Column: 3, Position: 4, Length: 2, Message: "Unterminated block comment"},
+	}, scnr.Errors, "Expecting the error at the opening of the comment.")
+	assert.Equal(t, 4, scnr.Tokens[1].Line, "Expecting the lines of the comment to be counted.")
+}
+
+func TestScanner_Scan_KeepComments(t *testing.T) {
+	scnr := Scanner{KeepComments: true}
+	scnr.Scan("// plain\n/// doc\n//// plain\nx /* a /* b */ */ /** doc */ /**/")
+
+	expected := []Token{
+		{Type: COMMENT, Lexeme: "// plain", Literal: " plain", Line: 1, Column: 1, Position: 0, Length: 8},
+		{Type: DOC_COMMENT, Lexeme: "/// doc", Literal: " doc", Line: 2, Column: 1, Position: 9, Length: 7},
+		{Type: COMMENT, Lexeme: "//// plain", Literal: "// plain", Line: 3, Column: 1, Position: 17, Length: 10},
+		{Type: IDENTIFIER, Lexeme: "x", Line: 4, Column: 1, Position: 28, Length: 1},
+		{Type: COMMENT, Lexeme: "/* a /* b */ */", Literal: " a /* b */ ", Line: 4, Column: 3, Position: 30, Length: 15},
+		{Type: DOC_COMMENT, Lexeme: "/** doc */", Literal: " doc ", Line: 4, Column: 19, Position: 46, Length: 10},
+		{Type: COMMENT, Lexeme: "/**/", Literal: "", Line: 4, Column: 30, Position: 57, Length: 4},
+		{Type: EOF, Lexeme: "EOF", Line: 4, Column: 34, Position: 61},
+	}
+	assert.False(t, scnr.HadError)
+	assert.Equal(t, expected, scnr.Tokens)
+}
+
+// collect reads the whole stream, the tokens and errors are returned separately like after Scan
+func collect(scnr *Scanner) ([]Token, []error) {
+	tokens := []Token{}
+	errs := []error{}
+	for {
+		tkn, err := scnr.Next()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tokens = append(tokens, tkn)
+		if tkn.Type == EOF {
+			return tokens, errs
+		}
+	}
+}
+
+func TestScanner_Next(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"test code", TestCode},
+		{"empty source", ""},
+		{"without trailing line break", "print 1;"},
+		{"multi line string", "x = \"a\nb\nc\"; y"},
+		{"interpolation spanning lines", "print \"a ${\n  \"b\" + \"${c\n}\"\n} d\";\nx"},
+		{"block comments spanning lines", "a /* b\n /* c */\n */ d\n/* open\n"},
+		{"unicode", "var größe = \"€\";\n  π\n"},
+		{"errors", "a @ b\n\"\\q\" 12abc\n\"open ${x"},
+		{"unterminated interpolation spanning lines", "y \"a ${\nx\n"},
+	}
+
+	for _, tt := range tests {
+		scnr := Scanner{}
+		scnr.Scan(tt.source)
+
+		stream := NewStreamScanner(iotest.OneByteReader(strings.NewReader(tt.source)))
+		tokens, errs := collect(stream)
+		assert.Equal(t, scnr.Tokens, tokens, "Expecting the stream to yield the tokens of Scan: "+tt.name)
+		assert.Equal(t, scnr.Errors, errs, "Expecting the stream to yield the errors of Scan: "+tt.name)
+		assert.Equal(t, scnr.HadError, stream.HadError, tt.name)
+	}
+}
+
+func TestScanner_Next_AfterEOF(t *testing.T) {
+	scnr := NewStreamScanner(strings.NewReader("x"))
+
+	scnr.Next()
+	for i := 0; i < 3; i++ {
+		tkn, err := scnr.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, EOF, tkn.Type, "Expecting EOF to be returned again at the end of the stream.")
+	}
+}
+
+func TestScanner_Next_KeepsOnlyTheCurrentLine(t *testing.T) {
+	line := "var a = \"some text\"; // comment\n"
+	scnr := NewStreamScanner(strings.NewReader(strings.Repeat(line, 1000)))
+
+	count := 0
+	for {
+		tkn, err := scnr.Next()
+		assert.NoError(t, err)
+		if tkn.Type == EOF {
+			break
+		}
+		assert.LessOrEqual(t, len(scnr.lines), 2*len(line), "Expecting the window to hold the current line only.")
+		count += 1
+	}
+	assert.Equal(t, 5000, count)
+	assert.Equal(t, 1001, scnr.Line)
+}
+
+func TestScanner_Channel(t *testing.T) {
+	scnr := NewStreamScanner(strings.NewReader("print 1 @;"))
+
+	types := []TokenType{}
+	for tkn := range scnr.Channel() {
+		types = append(types, tkn.Type)
+	}
+	assert.Equal(t, []TokenType{PRINT, NUMBER, SEMICOLON, EOF}, types)
+	assert.Len(t, scnr.Errors, 1, "Expecting the errors to be recorded.")
+}
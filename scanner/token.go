@@ -2,11 +2,15 @@ package scanner
 
 import "fmt"
 
+// Token is a lexeme of the source. Position is the byte offset of the token in the scanned source,
+// Column is the 1-based column of Position in its line, counted in runes.
+// Position, Column and Length of strings exclude the quotes.
 type Token struct {
 	Type     TokenType
 	Lexeme   string
 	Literal  interface{}
 	Line     int
+	Column   int
 	Position int
 	Length   int
 }
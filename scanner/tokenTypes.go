@@ -30,6 +30,12 @@ const (
 	IDENTIFIER
 	STRING
 	NUMBER
+	// INTERPOLATION is a string segment followed by an embedded expression: "segment${
+	INTERPOLATION
+
+	// Comments, only emitted, if the scanner keeps them.
+	COMMENT
+	DOC_COMMENT
 
 	// Keywords.
 
@@ -98,6 +104,12 @@ func (t TokenType) String() string {
 		return "STRING"
 	case NUMBER:
 		return "NUMBER"
+	case INTERPOLATION:
+		return "INTERPOLATION"
+	case COMMENT:
+		return "COMMENT"
+	case DOC_COMMENT:
+		return "DOC_COMMENT"
 	case AND:
 		return "AND"
 	case CLASS:
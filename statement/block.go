@@ -0,0 +1,9 @@
+package statement
+
+type Block struct {
+	Statements []Stmt
+}
+
+func (self Block) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitBlockStmt(self)
+}
@@ -0,0 +1,16 @@
+package statement
+
+import (
+	"github.com/th-lange/glox/expression"
+	"github.com/th-lange/glox/scanner"
+)
+
+type Class struct {
+	Name       scanner.Token
+	Superclass *expression.Variable
+	Methods    []Function
+}
+
+func (self Class) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitClassStmt(self)
+}
@@ -0,0 +1,13 @@
+package statement
+
+import (
+	"github.com/th-lange/glox/expression"
+)
+
+type Expression struct {
+	Expr expression.Expression
+}
+
+func (self Expression) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitExpressionStmt(self)
+}
@@ -0,0 +1,15 @@
+package statement
+
+import (
+	"github.com/th-lange/glox/scanner"
+)
+
+type Function struct {
+	Name   scanner.Token
+	Params []scanner.Token
+	Body   []Stmt
+}
+
+func (self Function) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitFunctionStmt(self)
+}
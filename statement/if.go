@@ -0,0 +1,15 @@
+package statement
+
+import (
+	"github.com/th-lange/glox/expression"
+)
+
+type If struct {
+	Condition  expression.Expression
+	ThenBranch Stmt
+	ElseBranch Stmt
+}
+
+func (self If) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitIfStmt(self)
+}
@@ -0,0 +1,13 @@
+package statement
+
+import (
+	"github.com/th-lange/glox/expression"
+)
+
+type Print struct {
+	Expr expression.Expression
+}
+
+func (self Print) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitPrintStmt(self)
+}
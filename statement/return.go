@@ -0,0 +1,15 @@
+package statement
+
+import (
+	"github.com/th-lange/glox/expression"
+	"github.com/th-lange/glox/scanner"
+)
+
+type Return struct {
+	Keyword scanner.Token
+	Value   expression.Expression
+}
+
+func (self Return) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitReturnStmt(self)
+}
@@ -0,0 +1,17 @@
+package statement
+
+type StmtVisitor interface {
+	VisitExpressionStmt(stmt Expression) interface{}
+	VisitPrintStmt(stmt Print) interface{}
+	VisitVarStmt(stmt Var) interface{}
+	VisitBlockStmt(stmt Block) interface{}
+	VisitIfStmt(stmt If) interface{}
+	VisitWhileStmt(stmt While) interface{}
+	VisitFunctionStmt(stmt Function) interface{}
+	VisitReturnStmt(stmt Return) interface{}
+	VisitClassStmt(stmt Class) interface{}
+}
+
+type Stmt interface {
+	Accept(StmtVisitor) interface{}
+}
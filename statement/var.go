@@ -0,0 +1,15 @@
+package statement
+
+import (
+	"github.com/th-lange/glox/expression"
+	"github.com/th-lange/glox/scanner"
+)
+
+type Var struct {
+	Name        scanner.Token
+	Initializer expression.Expression
+}
+
+func (self Var) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitVarStmt(self)
+}
@@ -0,0 +1,14 @@
+package statement
+
+import (
+	"github.com/th-lange/glox/expression"
+)
+
+type While struct {
+	Condition expression.Expression
+	Body      Stmt
+}
+
+func (self While) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitWhileStmt(self)
+}
@@ -1,6 +1,7 @@
 package statusCodes
 
 const (
-	EXIT_CODE_OK    = 0
-	EXIT_DATA_ERROR = 1 // EXIT_DATA_ERROR
+	EXIT_CODE_OK       = 0
+	EXIT_DATA_ERROR    = 1  // EXIT_DATA_ERROR
+	EXIT_RUNTIME_ERROR = 70 // EX_SOFTWARE, see sysexits.h
 )
@@ -17,37 +17,79 @@ type astDefElement struct {
 }
 
 type astDef struct {
-	Name           string
-	ImportRequired bool
-	Elements       []astDefElement
+	Name     string
+	Imports  []string
+	Elements []astDefElement
 }
 
+// astGroup bundles the definitions of one generated package, e.g. expressions or statements.
+// The first definition names the common interface, which every node of the group implements.
+type astGroup struct {
+	Visitor     string
+	Suffix      string
+	Param       string
+	Definitions []astDef
+}
+
+const scannerImport = "github.com/th-lange/glox/scanner"
+const expressionImport = "github.com/th-lange/glox/expression"
+
 var astDefinition = []astDef{
-	{"Expression", false, []astDefElement{}},
-	{"Binary", true, []astDefElement{{"Left", "Expression"}, {"Operator", "scanner.Token"}, {"Right", "Expression"}}},
-	{"Grouping", false, []astDefElement{{"Expr", "Expression"}}},
-	{"Literal", true, []astDefElement{{"Value", "scanner.Token"}}},
-	{"Unary", true, []astDefElement{{"Operator", "scanner.Token"}, {"Right", "Expression"}}},
+	{"Expression", nil, []astDefElement{}},
+	{"Binary", []string{scannerImport}, []astDefElement{{"Left", "Expression"}, {"Operator", "scanner.Token"}, {"Right", "Expression"}}},
+	{"Grouping", nil, []astDefElement{{"Expr", "Expression"}}},
+	{"Literal", []string{scannerImport}, []astDefElement{{"Value", "scanner.Token"}}},
+	{"Unary", []string{scannerImport}, []astDefElement{{"Operator", "scanner.Token"}, {"Right", "Expression"}}},
+	{"Variable", []string{scannerImport}, []astDefElement{{"Name", "scanner.Token"}}},
+	{"Assign", []string{scannerImport}, []astDefElement{{"Name", "scanner.Token"}, {"Value", "Expression"}}},
+	{"Logical", []string{scannerImport}, []astDefElement{{"Left", "Expression"}, {"Operator", "scanner.Token"}, {"Right", "Expression"}}},
+	{"Call", []string{scannerImport}, []astDefElement{{"Callee", "Expression"}, {"Paren", "scanner.Token"}, {"Arguments", "[]Expression"}}},
+	{"Get", []string{scannerImport}, []astDefElement{{"Object", "Expression"}, {"Name", "scanner.Token"}}},
+	{"Set", []string{scannerImport}, []astDefElement{{"Object", "Expression"}, {"Name", "scanner.Token"}, {"Value", "Expression"}}},
+	{"This", []string{scannerImport}, []astDefElement{{"Keyword", "scanner.Token"}}},
+	{"Super", []string{scannerImport}, []astDefElement{{"Keyword", "scanner.Token"}, {"Method", "scanner.Token"}}},
+	{"Interpolation", nil, []astDefElement{{"Parts", "[]Expression"}}},
+}
+
+var stmtDefinition = []astDef{
+	{"Stmt", nil, []astDefElement{}},
+	{"Expression", []string{expressionImport}, []astDefElement{{"Expr", "expression.Expression"}}},
+	{"Print", []string{expressionImport}, []astDefElement{{"Expr", "expression.Expression"}}},
+	{"Var", []string{expressionImport, scannerImport}, []astDefElement{{"Name", "scanner.Token"}, {"Initializer", "expression.Expression"}}},
+	{"Block", nil, []astDefElement{{"Statements", "[]Stmt"}}},
+	{"If", []string{expressionImport}, []astDefElement{{"Condition", "expression.Expression"}, {"ThenBranch", "Stmt"}, {"ElseBranch", "Stmt"}}},
+	{"While", []string{expressionImport}, []astDefElement{{"Condition", "expression.Expression"}, {"Body", "Stmt"}}},
+	{"Function", []string{scannerImport}, []astDefElement{{"Name", "scanner.Token"}, {"Params", "[]scanner.Token"}, {"Body", "[]Stmt"}}},
+	{"Return", []string{expressionImport, scannerImport}, []astDefElement{{"Keyword", "scanner.Token"}, {"Value", "expression.Expression"}}},
+	{"Class", []string{expressionImport, scannerImport}, []astDefElement{{"Name", "scanner.Token"}, {"Superclass", "*expression.Variable"}, {"Methods", "[]Function"}}},
 }
 
-func GenerateAst(homeDir, packageName string) {
+var expressionGroup = astGroup{"Visitor", "", "expression", astDefinition}
+var stmtGroup = astGroup{"StmtVisitor", "Stmt", "stmt", stmtDefinition}
+
+func GenerateAst(homeDir, expressionPackage, stmtPackage string) {
+	generateGroup(homeDir, expressionPackage, expressionGroup)
+	generateGroup(homeDir, stmtPackage, stmtGroup)
+}
+
+func generateGroup(homeDir, packageName string, group astGroup) {
 
 	basePath := homeDir + string(os.PathSeparator) + packageName
 	if _, err := os.Stat(basePath); os.IsNotExist(err) {
 		err := os.Mkdir(basePath, os.ModePerm)
-		checkErr(err, "Could not create path: "+basePath+"!\nError:"+err.Error())
+		checkErr(err, "Could not create path: "+basePath+"!")
 	}
-	writeToFile(basePath+string(os.PathSeparator)+"Warning.md", generateWarining())
+	writeToFile(basePath+string(os.PathSeparator)+"Warning.md", generateWarining(packageName, group))
 
 	itemTemplate, err := template.ParseFiles(homeDir + string(os.PathSeparator) + "util" + string(os.PathSeparator) + "astElementTemplate.tmpl")
 	checkErr(err, "Could not parse template!")
 	expressionTemplate, err := template.ParseFiles(homeDir + string(os.PathSeparator) + "util" + string(os.PathSeparator) + "expressionTemplate.tmpl")
 	checkErr(err, "Could not parse template!")
-	for _, element := range astDefinition {
-		if element.Name == "Expression" {
-			writeTemplate(packageName, err, expressionTemplate, basePath, &element, element.Name)
+	for i, element := range group.Definitions {
+		if i == 0 {
+			writeTemplate(packageName, err, expressionTemplate, basePath, &element, element.Name, group)
 		} else {
-			writeTemplate(packageName, err, itemTemplate, basePath, &element, element.Name)
+			writeTemplate(packageName, err, itemTemplate, basePath, &element, element.Name, group)
 		}
 	}
 }
@@ -56,18 +98,22 @@ func GenerateVisitor(homeDir, packageName, visitorName string) {
 	basePath := homeDir + string(os.PathSeparator) + packageName
 	if _, err := os.Stat(basePath); os.IsNotExist(err) {
 		err := os.Mkdir(basePath, os.ModePerm)
-		checkErr(err, "Could not create path: "+basePath+"!\nError:"+err.Error())
+		checkErr(err, "Could not create path: "+basePath+"!")
 	}
 	visitorTemplate, err := template.ParseFiles(homeDir + string(os.PathSeparator) + "util" + string(os.PathSeparator) + "visitorTemplate.tmpl")
-	writeTemplate(packageName, err, visitorTemplate, basePath, nil, visitorName)
+	writeTemplate(packageName, err, visitorTemplate, basePath, nil, visitorName, expressionGroup)
 
 }
 
-func writeTemplate(packageName string, err error, t *template.Template, basePath string, element *astDef, name string) {
+func writeTemplate(packageName string, err error, t *template.Template, basePath string, element *astDef, name string, group astGroup) {
 	var codeBuffer bytes.Buffer
 	err = t.Execute(&codeBuffer, TemplateMap{
 		"Self":     element,
-		"All":      astDefinition,
+		"All":      group.Definitions,
+		"Base":     group.Definitions[0].Name,
+		"Visitor":  group.Visitor,
+		"Suffix":   group.Suffix,
+		"Param":    group.Param,
 		"Package":  packageName,
 		"ItemName": name,
 	})
@@ -92,22 +138,20 @@ func checkErr(err error, msg string) {
 	}
 }
 
-func generateWarining() string {
+func generateWarining(packageName string, group astGroup) string {
+	files := ""
+	for _, element := range group.Definitions {
+		files += "- " + packageName + "/" + strings.ToLower(element.Name) + ".go\n"
+	}
 	return `# Warning
 
 This folder is autogenerated.
 Do not modify it's content! It might be overwritten!
 
-One can choose a different package name / folder to test and compare, using the "--target" or "-t" option
+One can choose a different package name / folder to test and compare, using the "--target" / "-t" and "--stmtTarget" / "-s" options
 
 ## These files get overwritten:
-- expression/binary.go
-- expression/expression.go
-- expression/expression.go
-- expression/grouping.go
-- expression/literal.go
-- expression/unary.go
-- expression/Warning.md
+` + files + "- " + packageName + `/Warning.md
 
 `
 }
@@ -24,6 +24,42 @@ func (visitor PrettyPrinter) VisitUnary(expression expression.Unary) interface{}
 	return visitor.parenthesize(expression.Operator.Lexeme, expression.Right)
 }
 
+func (visitor PrettyPrinter) VisitVariable(expression expression.Variable) interface{} {
+	return expression.Name.Lexeme
+}
+
+func (visitor PrettyPrinter) VisitAssign(expression expression.Assign) interface{} {
+	return visitor.parenthesize("= "+expression.Name.Lexeme, expression.Value)
+}
+
+func (visitor PrettyPrinter) VisitLogical(expression expression.Logical) interface{} {
+	return visitor.parenthesize(expression.Operator.Lexeme, expression.Left, expression.Right)
+}
+
+func (visitor PrettyPrinter) VisitCall(expr expression.Call) interface{} {
+	return visitor.parenthesize("call", append([]expression.Expression{expr.Callee}, expr.Arguments...)...)
+}
+
+func (visitor PrettyPrinter) VisitGet(expression expression.Get) interface{} {
+	return visitor.parenthesize("."+expression.Name.Lexeme, expression.Object)
+}
+
+func (visitor PrettyPrinter) VisitSet(expression expression.Set) interface{} {
+	return visitor.parenthesize("= ."+expression.Name.Lexeme, expression.Object, expression.Value)
+}
+
+func (visitor PrettyPrinter) VisitThis(expression expression.This) interface{} {
+	return expression.Keyword.Lexeme
+}
+
+func (visitor PrettyPrinter) VisitSuper(expression expression.Super) interface{} {
+	return expression.Keyword.Lexeme + "." + expression.Method.Lexeme
+}
+
+func (visitor PrettyPrinter) VisitInterpolation(expression expression.Interpolation) interface{} {
+	return visitor.parenthesize("interpolation", expression.Parts...)
+}
+
 func (visitor PrettyPrinter) parenthesize(name string, expression ...expression.Expression) string {
 	sb := strings.Builder{}
 
@@ -104,3 +104,16 @@ func TestPrettyPrinter_VisitUnary(t *testing.T) {
 	result := prettyPrinter.VisitUnary(unary)
 	assert.Equal(t, " ( + 1  ) ", result, "Expecting correct value for Literal.")
 }
+
+func TestPrettyPrinter_VisitAssign(t *testing.T) {
+	tokenX := scanner.Token{Lexeme: "x", Type: scanner.IDENTIFIER}
+	tokenY := scanner.Token{Lexeme: "y", Type: scanner.IDENTIFIER}
+
+	assign := expression.Assign{
+		Name:  tokenX,
+		Value: expression.Variable{Name: tokenY},
+	}
+
+	result := prettyPrinter.VisitAssign(assign)
+	assert.Equal(t, " ( = x y  ) ", result, "Expecting correct value for Assign.")
+}
@@ -24,6 +24,42 @@ func (visitor RPNPrinter) VisitUnary(expression expression.Unary) interface{} {
 	return visitor.renderAsReversePolishNotation(expression.Operator.Lexeme, expression.Right)
 }
 
+func (visitor RPNPrinter) VisitVariable(expression expression.Variable) interface{} {
+	return expression.Name.Lexeme
+}
+
+func (visitor RPNPrinter) VisitAssign(expression expression.Assign) interface{} {
+	return visitor.renderAsReversePolishNotation(expression.Name.Lexeme+" =", expression.Value)
+}
+
+func (visitor RPNPrinter) VisitLogical(expression expression.Logical) interface{} {
+	return visitor.renderAsReversePolishNotation(expression.Operator.Lexeme, expression.Left, expression.Right)
+}
+
+func (visitor RPNPrinter) VisitCall(expr expression.Call) interface{} {
+	return visitor.renderAsReversePolishNotation("call", append([]expression.Expression{expr.Callee}, expr.Arguments...)...)
+}
+
+func (visitor RPNPrinter) VisitGet(expression expression.Get) interface{} {
+	return visitor.renderAsReversePolishNotation("."+expression.Name.Lexeme, expression.Object)
+}
+
+func (visitor RPNPrinter) VisitSet(expression expression.Set) interface{} {
+	return visitor.renderAsReversePolishNotation("= ."+expression.Name.Lexeme, expression.Object, expression.Value)
+}
+
+func (visitor RPNPrinter) VisitThis(expression expression.This) interface{} {
+	return expression.Keyword.Lexeme
+}
+
+func (visitor RPNPrinter) VisitSuper(expression expression.Super) interface{} {
+	return expression.Keyword.Lexeme + "." + expression.Method.Lexeme
+}
+
+func (visitor RPNPrinter) VisitInterpolation(expression expression.Interpolation) interface{} {
+	return visitor.renderAsReversePolishNotation("interpolation", expression.Parts...)
+}
+
 func (visitor RPNPrinter) renderAsReversePolishNotation(name string, expression ...expression.Expression) string {
 	sb := strings.Builder{}
 	for _, itm := range expression {
@@ -0,0 +1,32 @@
+//go:build !nointern
+// +build !nointern
+
+package vm
+
+// Strings are interned, so the tables of globals, fields and methods are keyed by the string objects
+// and equal strings are identical. The nointern build tag turns the interning off, it is only meant
+// as the baseline of the benchmarks.
+const internStrings = true
+
+type tableKey = *String
+
+func keyOf(name *String) tableKey {
+	return name
+}
+
+// markKey marks the name of a table entry, it is an object of the heap
+func (vm *VM) markKey(name tableKey) {
+	vm.markObject(name)
+}
+
+// internString returns the string object with the value, it is only allocated if no such object exists yet.
+// The table doesn't keep the strings alive, unreachable strings are removed before the sweep.
+func (vm *VM) internString(value string) *String {
+	if str, ok := vm.strings[value]; ok {
+		return str
+	}
+	str := &String{Value: value}
+	vm.allocate(str, objectSize+len(value))
+	vm.strings[value] = str
+	return str
+}
@@ -0,0 +1,295 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/th-lange/glox/compiler"
+)
+
+const (
+	initialNextGC  = 1024 * 1024
+	heapGrowFactor = 2
+
+	// estimated sizes of the objects, used to decide when to collect
+	objectSize  = 48
+	pointerSize = 8
+)
+
+// allocate registers the object on the heap. The collector may run before, so everything the
+// new object references has to be reachable from the roots already.
+func (vm *VM) allocate(object Object, size int) {
+	if vm.GCStress {
+		vm.collectGarbage()
+	}
+	vm.bytesAllocated += size
+	if vm.bytesAllocated > vm.nextGC {
+		vm.collectGarbage()
+	}
+
+	header := object.header()
+	header.size = size
+	header.next = vm.objects
+	vm.objects = object
+}
+
+func (vm *VM) newClosure(function *compiler.Function) *Closure {
+	closure := &Closure{
+		Function:  function,
+		Upvalues:  make([]*Upvalue, function.UpvalueCount),
+		constants: vm.constantsOf(function),
+	}
+	vm.allocate(closure, objectSize+pointerSize*function.UpvalueCount)
+	vm.pendingConstants = nil
+	return closure
+}
+
+func (vm *VM) newUpvalue(slot int) *Upvalue {
+	upvalue := &Upvalue{location: &vm.stack[slot], slot: slot}
+	vm.allocate(upvalue, objectSize)
+	return upvalue
+}
+
+func (vm *VM) newClass(name *String) *Class {
+	class := &Class{Name: name, Methods: make(map[tableKey]*Closure)}
+	vm.allocate(class, objectSize)
+	return class
+}
+
+func (vm *VM) newInstance(class *Class) *Instance {
+	instance := &Instance{Class: class, Fields: make(map[tableKey]Value)}
+	vm.allocate(instance, objectSize)
+	return instance
+}
+
+func (vm *VM) newBoundMethod(receiver Value, method *Closure) *BoundMethod {
+	bound := &BoundMethod{Receiver: receiver, Method: method}
+	vm.allocate(bound, objectSize)
+	return bound
+}
+
+func (vm *VM) newNative(name string, arity int, function func(arguments []interface{}) (interface{}, error)) *Native {
+	native := &Native{Name: name, Arity: arity, Function: function}
+	vm.allocate(native, objectSize+len(name))
+	return native
+}
+
+// constantTable holds the runtime values of the constants of a function, it is shared by all closures of the function.
+// The tables are weak like the intern table: the collector drops a table, when no reachable closure uses it any more.
+type constantTable struct {
+	values   []Value
+	size     int
+	isMarked bool
+}
+
+// constantsOf converts the constants of the chunk into runtime values once per function.
+// Nested functions stay nil, OP_CLOSURE reads them from the chunk directly.
+// Until the new closure is allocated, the table is only reachable as pendingConstants, newClosure clears it.
+func (vm *VM) constantsOf(function *compiler.Function) []Value {
+	table, ok := vm.constants[function]
+	if !ok {
+		table = &constantTable{
+			values: make([]Value, len(function.Chunk.Constants)),
+			size:   objectSize + len(function.Chunk.Code) + 2*pointerSize*len(function.Chunk.Constants),
+		}
+		vm.constants[function] = table
+		vm.bytesAllocated += table.size
+	}
+	// a root before the strings are allocated, so a collection in between can reach them
+	vm.pendingConstants = table
+	if ok {
+		return table.values
+	}
+	for i, constant := range function.Chunk.Constants {
+		switch constant := constant.(type) {
+		case float64:
+			table.values[i] = NumberValue(constant)
+		case string:
+			table.values[i] = ObjectValue(vm.internString(constant))
+		}
+	}
+	return table.values
+}
+
+// collectGarbage marks everything reachable from the roots and frees all other objects.
+func (vm *VM) collectGarbage() {
+	before := vm.bytesAllocated
+	if vm.Debug >= DEBUG_GC {
+		fmt.Fprintln(vm.out, "-- gc begin")
+	}
+
+	vm.markRoots()
+	vm.traceReferences()
+	vm.removeUnusedConstants()
+	vm.removeWhiteStrings()
+	vm.sweep()
+
+	vm.nextGC = vm.bytesAllocated * heapGrowFactor
+	if vm.nextGC < initialNextGC {
+		vm.nextGC = initialNextGC
+	}
+	if vm.Debug >= DEBUG_GC {
+		fmt.Fprintf(vm.out, "-- gc end: collected %d bytes (from %d to %d), next at %d\n", before-vm.bytesAllocated, before, vm.bytesAllocated, vm.nextGC)
+	}
+}
+
+func (vm *VM) markRoots() {
+	for _, value := range vm.stack[:vm.stackTop] {
+		vm.markValue(value)
+	}
+	for i := 0; i < vm.frameCount; i++ {
+		vm.markObject(vm.frames[i].closure)
+	}
+	for upvalue := vm.openUpvalues; upvalue != nil; upvalue = upvalue.nextOpen {
+		vm.markObject(upvalue)
+	}
+	for name, value := range vm.globals {
+		vm.markKey(name)
+		vm.markValue(value)
+	}
+	vm.markObject(vm.initString)
+	if vm.pendingConstants != nil {
+		vm.markConstants(vm.pendingConstants)
+	}
+}
+
+func (vm *VM) markConstants(table *constantTable) {
+	if table.isMarked {
+		return
+	}
+	table.isMarked = true
+	for _, constant := range table.values {
+		vm.markValue(constant)
+	}
+}
+
+func (vm *VM) markValue(value Value) {
+	if object := value.AsObject(); object != nil {
+		vm.markObject(object)
+	}
+}
+
+// markObject colors the object gray, its references are marked later by traceReferences
+func (vm *VM) markObject(object Object) {
+	header := object.header()
+	if header.isMarked {
+		return
+	}
+	header.isMarked = true
+	vm.grayStack = append(vm.grayStack, object)
+}
+
+func (vm *VM) traceReferences() {
+	for len(vm.grayStack) > 0 {
+		object := vm.grayStack[len(vm.grayStack)-1]
+		vm.grayStack = vm.grayStack[:len(vm.grayStack)-1]
+		vm.blackenObject(object)
+	}
+}
+
+// blackenObject marks all objects referenced by the object
+func (vm *VM) blackenObject(object Object) {
+	switch object := object.(type) {
+	case *Closure:
+		vm.markConstants(vm.constants[object.Function])
+		for _, upvalue := range object.Upvalues {
+			if upvalue != nil {
+				vm.markObject(upvalue)
+			}
+		}
+	case *Upvalue:
+		vm.markValue(*object.location)
+	case *Class:
+		vm.markObject(object.Name)
+		for name, method := range object.Methods {
+			vm.markKey(name)
+			vm.markObject(method)
+		}
+	case *Instance:
+		vm.markObject(object.Class)
+		for name, value := range object.Fields {
+			vm.markKey(name)
+			vm.markValue(value)
+		}
+	case *BoundMethod:
+		vm.markValue(object.Receiver)
+		vm.markObject(object.Method)
+	}
+}
+
+// removeUnusedConstants drops the constant tables, that no marked closure uses
+func (vm *VM) removeUnusedConstants() {
+	for function, table := range vm.constants {
+		if !table.isMarked {
+			delete(vm.constants, function)
+			vm.bytesAllocated -= table.size
+			continue
+		}
+		table.isMarked = false
+	}
+}
+
+// removeWhiteStrings drops the unmarked strings from the intern table, they are freed by the following sweep
+func (vm *VM) removeWhiteStrings() {
+	for value, str := range vm.strings {
+		if !str.isMarked {
+			delete(vm.strings, value)
+		}
+	}
+}
+
+// sweep unlinks all unmarked objects and clears the marks of the surviving ones
+func (vm *VM) sweep() {
+	var previous Object
+	object := vm.objects
+	for object != nil {
+		header := object.header()
+		if header.isMarked {
+			header.isMarked = false
+			previous = object
+			object = header.next
+			continue
+		}
+
+		unreached := object
+		object = header.next
+		if previous == nil {
+			vm.objects = object
+		} else {
+			previous.header().next = object
+		}
+		vm.free(unreached)
+	}
+}
+
+// free releases the references of the object. Any later use of the object fails loudly,
+// which makes --gc-stress runs reveal objects, that were freed while still being in use.
+func (vm *VM) free(object Object) {
+	if vm.Debug >= DEBUG_GC {
+		fmt.Fprintf(vm.out, "free %T %s\n", object, Stringify(ObjectValue(object)))
+	}
+	header := object.header()
+	vm.bytesAllocated -= header.size
+	header.next = nil
+
+	switch object := object.(type) {
+	case *String:
+		object.Value = "<freed string>"
+	case *Closure:
+		object.Function = nil
+		object.Upvalues = nil
+		object.constants = nil
+	case *Upvalue:
+		object.location = nil
+		object.closed = NilValue()
+	case *Class:
+		object.Methods = nil
+	case *Instance:
+		object.Class = nil
+		object.Fields = nil
+	case *BoundMethod:
+		object.Receiver = NilValue()
+		object.Method = nil
+	case *Native:
+		object.Function = nil
+	}
+}
@@ -0,0 +1,123 @@
+package vm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countObjects(vm *VM) int {
+	count := 0
+	for object := vm.objects; object != nil; object = object.header().next {
+		count++
+	}
+	return count
+}
+
+// skipWithoutInterning skips the tests, which count or compare the interned strings
+func skipWithoutInterning(t *testing.T) {
+	if !internStrings {
+		t.Skip("The strings are not interned.")
+	}
+}
+
+func TestVM_CollectGarbage_FreesUnreachableObjects(t *testing.T) {
+	skipWithoutInterning(t)
+	out := bytes.Buffer{}
+	vm := NewVM(&out)
+
+	err := vm.Interpret(parseProgram(t, `class Node {}
+var keep = Node();
+keep.name = "kept" + "!";
+for (var i = 0; i < 100; i = i + 1) {
+  var garbage = Node();
+  garbage.name = "garbage" + "!";
+}`), nil)
+	assert.NoError(t, err)
+	before := countObjects(vm)
+
+	vm.collectGarbage()
+	assert.Less(t, countObjects(vm), before-99, "Expecting the instances of the loop to be freed.")
+	assert.Empty(t, vm.grayStack)
+	assert.NotContains(t, vm.strings, "garbage!", "Expecting unreachable strings to be removed from the intern table.")
+	assert.Contains(t, vm.strings, "kept!")
+
+	assert.NoError(t, vm.Interpret(parseProgram(t, "print keep.name;"), nil))
+	assert.Equal(t, "kept!\n", out.String(), "Expecting reachable objects to survive.")
+}
+
+func TestVM_CollectGarbage_KeepsRoots(t *testing.T) {
+	out := bytes.Buffer{}
+	vm := NewVM(&out)
+	vm.GCStress = true
+
+	err := vm.Interpret(parseProgram(t, `fun counter() {
+  var count = "count ";
+  fun increment() {
+    count = count + "I";
+    return count;
+  }
+  return increment;
+}
+var next = counter();
+{
+  var local = "on" + " stack";
+  next();
+  print next() + ", " + local;
+}
+class Base { name() { return "base" + "!"; } }
+class Derived < Base { name() { var method = super.name; return method(); } }
+print Derived().name();`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "count II, on stack\nbase!\n", out.String(), "Expecting no object in use to be freed.")
+}
+
+func TestVM_CollectGarbage_Log(t *testing.T) {
+	skipWithoutInterning(t)
+	out := bytes.Buffer{}
+	vm := NewVM(&out)
+	vm.Debug = DEBUG_GC
+
+	vm.allocate(&String{Value: "unreachable"}, objectSize)
+	vm.collectGarbage()
+
+	log := out.String()
+	assert.True(t, strings.HasPrefix(log, "-- gc begin\n"), "Expecting the collection to be logged: "+log)
+	assert.Contains(t, log, "free *vm.String unreachable\n")
+	assert.Contains(t, log, "-- gc end: collected 48 bytes")
+	assert.NotContains(t, log, "clock", "Expecting the native functions to stay alive.")
+}
+
+func TestVM_InternString(t *testing.T) {
+	skipWithoutInterning(t)
+	out := bytes.Buffer{}
+	vm := NewVM(&out)
+
+	assert.Same(t, vm.internString("field"), vm.internString("field"), "Expecting equal strings to share one object.")
+	assert.NotSame(t, vm.internString("field"), vm.internString("other"))
+
+	err := vm.Interpret(parseProgram(t, `var a = "con" + "cat";
+print a == "concat";
+print a == "con";`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "true\nfalse\n", out.String(), "Expecting concatenated strings to be interned as well.")
+}
+
+func TestVM_CollectGarbage_DropsConstantsOfFinishedPrograms(t *testing.T) {
+	out := bytes.Buffer{}
+	vm := NewVM(&out)
+
+	for i := 0; i < 1000; i++ {
+		err := vm.Interpret(parseProgram(t, `var text = "constant";
+fun kept() { return text + " of a function"; }`), nil)
+		assert.NoError(t, err)
+	}
+	vm.collectGarbage()
+	assert.Len(t, vm.constants, 1, "Expecting only the constants of the reachable closure to be kept.")
+	assert.Less(t, vm.bytesAllocated, 2048, "Expecting the memory of the finished programs to be freed.")
+
+	assert.NoError(t, vm.Interpret(parseProgram(t, "print kept();"), nil))
+	assert.Equal(t, "constant of a function\n", out.String(), "Expecting the constants of the kept closure to survive.")
+}
@@ -0,0 +1,22 @@
+//go:build nointern
+// +build nointern
+
+package vm
+
+// Without interning, the tables are keyed by the go strings and strings are compared by their content.
+// Equal strings are separate objects, every concatenation and constant allocates a new one.
+const internStrings = false
+
+type tableKey = string
+
+func keyOf(name *String) tableKey {
+	return name.Value
+}
+
+func (vm *VM) markKey(name tableKey) {}
+
+func (vm *VM) internString(value string) *String {
+	str := &String{Value: value}
+	vm.allocate(str, objectSize+len(value))
+	return str
+}
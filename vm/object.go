@@ -0,0 +1,99 @@
+package vm
+
+import (
+	"github.com/th-lange/glox/compiler"
+)
+
+// Object is implemented by all values, which the vm allocates on its heap.
+// Numbers, booleans and nil are stored directly in the Value.
+type Object interface {
+	header() *objectHeader
+}
+
+// objectHeader links all allocated objects, so the collector can sweep the unreachable ones.
+type objectHeader struct {
+	isMarked bool
+	size     int
+	next     Object
+}
+
+func (h *objectHeader) header() *objectHeader {
+	return h
+}
+
+// String is interned, so two strings with the same content are the same object.
+type String struct {
+	objectHeader
+	Value string
+}
+
+func (str *String) String() string {
+	return str.Value
+}
+
+// Closure is a compiled function together with the variables it captured.
+// constants are the runtime values of the constants in the chunk of the function, nested functions are nil there.
+type Closure struct {
+	objectHeader
+	Function  *compiler.Function
+	Upvalues  []*Upvalue
+	constants []Value
+}
+
+func (closure *Closure) String() string {
+	return closure.Function.String()
+}
+
+// Upvalue references a captured variable. While the variable is still on the stack, location points into the stack.
+// When the variable goes out of scope, the value is moved into closed and location points there.
+type Upvalue struct {
+	objectHeader
+	location *Value
+	closed   Value
+	slot     int
+	nextOpen *Upvalue
+}
+
+type Class struct {
+	objectHeader
+	Name    *String
+	Methods map[tableKey]*Closure
+}
+
+func (class *Class) String() string {
+	return class.Name.Value
+}
+
+type Instance struct {
+	objectHeader
+	Class  *Class
+	Fields map[tableKey]Value
+}
+
+func (instance *Instance) String() string {
+	return instance.Class.Name.Value + " instance"
+}
+
+// BoundMethod is a method, which was accessed on an instance and remembers it as receiver.
+type BoundMethod struct {
+	objectHeader
+	Receiver Value
+	Method   *Closure
+}
+
+func (bound *BoundMethod) String() string {
+	return bound.Method.String()
+}
+
+// Native makes a go function callable from lox code.
+// Errors returned by the function are reported as RuntimeError at the call site.
+type Native struct {
+	objectHeader
+	Name     string
+	Arity    int
+	Function func(arguments []interface{}) (interface{}, error)
+}
+
+func (native *Native) String() string {
+	return "<native fn " + native.Name + ">"
+}
@@ -0,0 +1,34 @@
+package vm
+
+import (
+	"strconv"
+
+	"github.com/th-lange/glox/diagnostic"
+)
+
+// Indicates that the EXECUTED bytecode failed, e.g. by adding a number to a string.
+// The position is taken from the line table of the chunk, Offset is -1 if it is unknown.
+type RuntimeError struct {
+	Line    int
+	Column  int
+	Offset  int
+	Length  int
+	Message string
+}
+
+func (re RuntimeError) Error() string {
+	if re.Column > 0 {
+		return "[Line " + strconv.Itoa(re.Line) + "] RuntimeError (Column " + strconv.Itoa(re.Column) + "): " + re.Message
+	}
+	return "[Line " + strconv.Itoa(re.Line) + "] RuntimeError: " + re.Message
+}
+
+func (re RuntimeError) Diagnostic() diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{
+		Code:    diagnostic.RUNTIME_ERROR,
+		Message: re.Message,
+		Line:    re.Line,
+		Offset:  re.Offset,
+		Length:  re.Length,
+	}
+}
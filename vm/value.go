@@ -0,0 +1,82 @@
+package vm
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// nil and false are falsey, everything else is truthy
+func isFalsey(value Value) bool {
+	return value.IsNil() || value.IsBool() && !value.AsBool()
+}
+
+// valuesEqual compares objects by identity. Strings are interned, so equal strings are identical.
+// The constructors keep the unused fields zero, so the values can be compared as a whole. NaN is still not equal to itself.
+func valuesEqual(left, right Value) bool {
+	if !internStrings {
+		leftString, lok := left.AsObject().(*String)
+		rightString, rok := right.AsObject().(*String)
+		if lok && rok {
+			return leftString.Value == rightString.Value
+		}
+	}
+	return left == right
+}
+
+// toNative hands strings as go strings to native functions, all other values are passed as their go equivalent
+func toNative(value Value) interface{} {
+	switch {
+	case value.IsBool():
+		return value.AsBool()
+	case value.IsNumber():
+		return value.AsNumber()
+	}
+	if str, ok := value.AsObject().(*String); ok {
+		return str.Value
+	}
+	if object := value.AsObject(); object != nil {
+		return object
+	}
+	return nil
+}
+
+// fromNative converts the result of a native function, strings are moved to the heap.
+// Go types without a lox equivalent are reported as runtime error, like the tree-walker does.
+func (vm *VM) fromNative(value interface{}) Value {
+	switch v := value.(type) {
+	case nil:
+		return NilValue()
+	case bool:
+		return BoolValue(v)
+	case float64:
+		return NumberValue(v)
+	case string:
+		return ObjectValue(vm.internString(v))
+	case Object:
+		return ObjectValue(v)
+	}
+	vm.runtimeError(fmt.Sprintf("Native returned unsupported type %T.", value))
+	return NilValue()
+}
+
+// Stringify renders a runtime value the way lox prints it.
+func Stringify(value Value) string {
+	switch {
+	case value.IsNil():
+		return "nil"
+	case value.IsBool():
+		return strconv.FormatBool(value.AsBool())
+	case value.IsNumber():
+		return strconv.FormatFloat(value.AsNumber(), 'f', -1, 64)
+	}
+	if str, ok := value.AsObject().(*String); ok {
+		return str.Value
+	}
+	return fmt.Sprint(value.AsObject())
+}
+
+// clock returns the seconds since the unix epoch
+func clock(arguments []interface{}) (interface{}, error) {
+	return float64(time.Now().UnixNano()) / float64(time.Second), nil
+}
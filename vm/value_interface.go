@@ -0,0 +1,57 @@
+//go:build interfacevalues
+// +build interfacevalues
+
+package vm
+
+// Value boxes every runtime value into an interface{}, like the vm did before the tagged union.
+// Every number and boolean costs an allocation. It is only the baseline of the benchmarks,
+// built with the interfacevalues tag.
+type Value struct {
+	value interface{}
+}
+
+func NilValue() Value {
+	return Value{}
+}
+
+func BoolValue(boolean bool) Value {
+	return Value{boolean}
+}
+
+func NumberValue(number float64) Value {
+	return Value{number}
+}
+
+func ObjectValue(object Object) Value {
+	return Value{object}
+}
+
+func (value Value) IsNil() bool {
+	return value.value == nil
+}
+
+func (value Value) IsBool() bool {
+	_, ok := value.value.(bool)
+	return ok
+}
+
+func (value Value) IsNumber() bool {
+	_, ok := value.value.(float64)
+	return ok
+}
+
+func (value Value) AsBool() bool {
+	boolean, _ := value.value.(bool)
+	return boolean
+}
+
+func (value Value) AsNumber() float64 {
+	number, _ := value.value.(float64)
+	return number
+}
+
+// AsObject returns nil for all values, which don't reference an object
+func (value Value) AsObject() Object {
+	object, _ := value.value.(Object)
+	return object
+}
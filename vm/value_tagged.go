@@ -0,0 +1,68 @@
+//go:build !interfacevalues
+// +build !interfacevalues
+
+package vm
+
+type ValueType byte
+
+const (
+	VAL_NIL ValueType = iota
+	VAL_BOOL
+	VAL_NUMBER
+	VAL_OBJECT
+)
+
+// Value is a runtime value of the vm. It is a tagged union, so numbers and booleans are stored
+// directly in the stack slots instead of being boxed into an interface{} with an allocation each.
+// Booleans share the number field, true is 1. NaN-boxing would save another 16 bytes per value,
+// but hiding object pointers in a float64 would hide them from the go garbage collector as well.
+// The zero value is nil.
+type Value struct {
+	Type   ValueType
+	number float64
+	object Object
+}
+
+func NilValue() Value {
+	return Value{}
+}
+
+func BoolValue(boolean bool) Value {
+	if boolean {
+		return Value{Type: VAL_BOOL, number: 1}
+	}
+	return Value{Type: VAL_BOOL}
+}
+
+func NumberValue(number float64) Value {
+	return Value{Type: VAL_NUMBER, number: number}
+}
+
+func ObjectValue(object Object) Value {
+	return Value{Type: VAL_OBJECT, object: object}
+}
+
+func (value Value) IsNil() bool {
+	return value.Type == VAL_NIL
+}
+
+func (value Value) IsBool() bool {
+	return value.Type == VAL_BOOL
+}
+
+func (value Value) IsNumber() bool {
+	return value.Type == VAL_NUMBER
+}
+
+func (value Value) AsBool() bool {
+	return value.number != 0
+}
+
+func (value Value) AsNumber() float64 {
+	return value.number
+}
+
+// AsObject returns nil for all values, which don't reference an object
+func (value Value) AsObject() Object {
+	return value.object
+}
@@ -0,0 +1,42 @@
+package vm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue_Equal(t *testing.T) {
+	str := &String{Value: "a"}
+
+	assert.True(t, valuesEqual(NilValue(), NilValue()))
+	assert.True(t, valuesEqual(BoolValue(true), BoolValue(true)))
+	assert.True(t, valuesEqual(NumberValue(0), NumberValue(math.Copysign(0, -1))))
+	assert.True(t, valuesEqual(ObjectValue(str), ObjectValue(str)))
+
+	assert.False(t, valuesEqual(NilValue(), BoolValue(false)), "Expecting the type to be compared.")
+	assert.False(t, valuesEqual(NumberValue(0), BoolValue(false)), "Expecting the type to be compared.")
+	assert.False(t, valuesEqual(NumberValue(1), BoolValue(true)), "Expecting the type to be compared.")
+	assert.False(t, valuesEqual(NumberValue(math.NaN()), NumberValue(math.NaN())), "Expecting NaN to differ from itself.")
+	if internStrings {
+		assert.False(t, valuesEqual(ObjectValue(str), ObjectValue(&String{Value: "a"})), "Expecting objects to be compared by identity.")
+	} else {
+		assert.True(t, valuesEqual(ObjectValue(str), ObjectValue(&String{Value: "a"})), "Expecting strings to be compared by content.")
+	}
+}
+
+func TestValue_IsFalsey(t *testing.T) {
+	assert.True(t, isFalsey(NilValue()))
+	assert.True(t, isFalsey(BoolValue(false)))
+	assert.False(t, isFalsey(BoolValue(true)))
+	assert.False(t, isFalsey(NumberValue(0)))
+	assert.False(t, isFalsey(ObjectValue(&String{})))
+}
+
+func TestStringify(t *testing.T) {
+	assert.Equal(t, "nil", Stringify(NilValue()))
+	assert.Equal(t, "false", Stringify(BoolValue(false)))
+	assert.Equal(t, "2.5", Stringify(NumberValue(2.5)))
+	assert.Equal(t, "text", Stringify(ObjectValue(&String{Value: "text"})))
+}
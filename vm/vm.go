@@ -0,0 +1,529 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/th-lange/glox/compiler"
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+)
+
+const (
+	framesMax = 256
+	stackMax  = framesMax * 256
+)
+
+// Debug levels of the vm, every level includes the output of the lower ones.
+const (
+	DEBUG_BYTECODE int8 = 1 // dump the compiled bytecode before running it
+	DEBUG_TRACE    int8 = 2 // print the stack and the instruction before every step
+	DEBUG_GC       int8 = 3 // log every collection and every freed object
+)
+
+// callFrame is a running function call. slots is the index of the called closure on the stack,
+// the arguments and locals of the call follow directly after it.
+type callFrame struct {
+	closure *Closure
+	ip      int
+	slots   int
+}
+
+// VM executes the bytecode of the compiler on a value stack.
+// Output of print statements and debug output is written to out. Globals survive between the interpreted programs.
+// Strings, closures, classes and instances live on the heap of the vm, which is cleaned up by a mark-and-sweep collector.
+// With GCStress the collector runs on every allocation.
+type VM struct {
+	Debug        int8
+	GCStress     bool
+	out          io.Writer
+	stack        []Value
+	stackTop     int
+	frames       []callFrame
+	frameCount   int
+	globals      map[tableKey]Value
+	openUpvalues *Upvalue
+	strings      map[string]*String
+	initString   *String
+
+	objects        Object
+	grayStack      []Object
+	bytesAllocated int
+	nextGC         int
+	// constants of the functions with closures, pendingConstants is the table of a closure being created
+	constants        map[*compiler.Function]*constantTable
+	pendingConstants *constantTable
+}
+
+func NewVM(out io.Writer) *VM {
+	vm := &VM{
+		out:       out,
+		stack:     make([]Value, stackMax),
+		frames:    make([]callFrame, framesMax),
+		globals:   make(map[tableKey]Value),
+		strings:   make(map[string]*String),
+		nextGC:    initialNextGC,
+		constants: make(map[*compiler.Function]*constantTable),
+	}
+	vm.initString = vm.internString("init")
+	vm.RegisterNative("clock", 0, clock)
+	return vm
+}
+
+// RegisterNative exposes a go function to the lox scripts as global function.
+func (vm *VM) RegisterNative(name string, arity int, function func(arguments []interface{}) (interface{}, error)) {
+	native := vm.newNative(name, arity, function)
+	// the native is only reachable through the stack until it is stored as global
+	vm.push(ObjectValue(native))
+	vm.globals[keyOf(vm.internString(name))] = ObjectValue(native)
+	vm.pop()
+}
+
+// Interpret compiles the statements and runs the resulting bytecode.
+// The locals of the resolver are not needed, the compiler assigns the stack slots itself.
+func (vm *VM) Interpret(statements []statement.Stmt, locals map[scanner.Token]int) error {
+	function, err := compiler.Compile(statements)
+	if err != nil {
+		return err
+	}
+	if vm.Debug >= DEBUG_BYTECODE {
+		compiler.Disassemble(vm.out, function)
+	}
+	return vm.Run(function)
+}
+
+// Run executes a compiled program and reports a RuntimeError instead of panicking.
+func (vm *VM) Run(function *compiler.Function) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			runtimeError, ok := r.(RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			vm.resetStack()
+			err = runtimeError
+		}
+	}()
+
+	closure := vm.newClosure(function)
+	vm.push(ObjectValue(closure))
+	vm.call(closure, 0)
+	vm.run()
+	return nil
+}
+
+func (vm *VM) resetStack() {
+	vm.stackTop = 0
+	vm.frameCount = 0
+	vm.openUpvalues = nil
+}
+
+// runtimeError aborts the execution, the error is reported for the instruction currently executed
+func (vm *VM) runtimeError(message string) {
+	vm.runtimeErrorAt(1, message)
+}
+
+// runtimeErrorAt reports the error at the source position of the byte "back" bytes before the instruction pointer.
+// Invokes record the method name for their name operand, which precedes the argument count.
+func (vm *VM) runtimeErrorAt(back int, message string) {
+	frame := &vm.frames[vm.frameCount-1]
+	location := frame.closure.Function.Chunk.Location(frame.ip - back)
+	offset := location.Position
+	if location.Column == 0 {
+		offset = -1
+	}
+	panic(RuntimeError{Line: location.Line, Column: location.Column, Offset: offset, Length: location.Length, Message: message})
+}
+
+// asClass returns the class the compiler placed on the stack. Loaded bytecode is not checked that deep,
+// so a broken file is reported as runtime error instead of crashing the vm.
+func (vm *VM) asClass(value Value) *Class {
+	class, ok := value.AsObject().(*Class)
+	if !ok {
+		vm.runtimeError("Expected a class.")
+	}
+	return class
+}
+
+func (vm *VM) push(value Value) {
+	if vm.stackTop == len(vm.stack) {
+		vm.runtimeError("Stack overflow.")
+	}
+	vm.stack[vm.stackTop] = value
+	vm.stackTop++
+}
+
+func (vm *VM) pop() Value {
+	vm.stackTop--
+	return vm.stack[vm.stackTop]
+}
+
+func (vm *VM) peek(distance int) Value {
+	return vm.stack[vm.stackTop-1-distance]
+}
+
+func (frame *callFrame) readByte() byte {
+	b := frame.closure.Function.Chunk.Code[frame.ip]
+	frame.ip++
+	return b
+}
+
+func (frame *callFrame) readShort() int {
+	code := frame.closure.Function.Chunk.Code
+	frame.ip += 2
+	return int(code[frame.ip-2])<<8 | int(code[frame.ip-1])
+}
+
+func (frame *callFrame) readConstant() Value {
+	return frame.closure.constants[frame.readShort()]
+}
+
+func (frame *callFrame) readString() *String {
+	return frame.readConstant().AsObject().(*String)
+}
+
+// run is the dispatch loop. It returns, when the script itself returns.
+func (vm *VM) run() {
+	frame := &vm.frames[vm.frameCount-1]
+	for {
+		if vm.Debug >= DEBUG_TRACE {
+			vm.traceInstruction(frame)
+		}
+		switch compiler.OpCode(frame.readByte()) {
+		case compiler.OP_CONSTANT:
+			vm.push(frame.readConstant())
+		case compiler.OP_NIL:
+			vm.push(NilValue())
+		case compiler.OP_TRUE:
+			vm.push(BoolValue(true))
+		case compiler.OP_FALSE:
+			vm.push(BoolValue(false))
+		case compiler.OP_POP:
+			vm.pop()
+
+		case compiler.OP_GET_LOCAL:
+			vm.push(vm.stack[frame.slots+int(frame.readByte())])
+		case compiler.OP_SET_LOCAL:
+			vm.stack[frame.slots+int(frame.readByte())] = vm.peek(0)
+		case compiler.OP_GET_GLOBAL:
+			name := frame.readString()
+			value, ok := vm.globals[keyOf(name)]
+			if !ok {
+				vm.runtimeError("Undefined variable '" + name.Value + "'.")
+			}
+			vm.push(value)
+		case compiler.OP_DEFINE_GLOBAL:
+			vm.globals[keyOf(frame.readString())] = vm.pop()
+		case compiler.OP_SET_GLOBAL:
+			name := frame.readString()
+			if _, ok := vm.globals[keyOf(name)]; !ok {
+				vm.runtimeError("Undefined variable '" + name.Value + "'.")
+			}
+			vm.globals[keyOf(name)] = vm.peek(0)
+		case compiler.OP_GET_UPVALUE:
+			vm.push(*frame.closure.Upvalues[frame.readByte()].location)
+		case compiler.OP_SET_UPVALUE:
+			*frame.closure.Upvalues[frame.readByte()].location = vm.peek(0)
+		case compiler.OP_GET_PROPERTY:
+			name := frame.readString()
+			instance, ok := vm.peek(0).AsObject().(*Instance)
+			if !ok {
+				vm.runtimeError("Only instances have properties.")
+			}
+			if value, ok := instance.Fields[keyOf(name)]; ok {
+				vm.pop()
+				vm.push(value)
+				break
+			}
+			vm.bindMethod(instance.Class, name)
+		case compiler.OP_SET_PROPERTY:
+			name := frame.readString()
+			instance, ok := vm.peek(1).AsObject().(*Instance)
+			if !ok {
+				vm.runtimeError("Only instances have fields.")
+			}
+			instance.Fields[keyOf(name)] = vm.peek(0)
+			value := vm.pop()
+			vm.pop()
+			vm.push(value)
+		case compiler.OP_GET_SUPER:
+			name := frame.readString()
+			vm.bindMethod(vm.asClass(vm.pop()), name)
+
+		case compiler.OP_EQUAL:
+			right := vm.pop()
+			vm.push(BoolValue(valuesEqual(vm.pop(), right)))
+		case compiler.OP_GREATER:
+			left, right := vm.numberOperands()
+			vm.push(BoolValue(left > right))
+		case compiler.OP_GREATER_EQUAL:
+			left, right := vm.numberOperands()
+			vm.push(BoolValue(left >= right))
+		case compiler.OP_LESS:
+			left, right := vm.numberOperands()
+			vm.push(BoolValue(left < right))
+		case compiler.OP_LESS_EQUAL:
+			left, right := vm.numberOperands()
+			vm.push(BoolValue(left <= right))
+		case compiler.OP_ADD:
+			vm.add()
+		case compiler.OP_SUBTRACT:
+			left, right := vm.numberOperands()
+			vm.push(NumberValue(left - right))
+		case compiler.OP_MULTIPLY:
+			left, right := vm.numberOperands()
+			vm.push(NumberValue(left * right))
+		case compiler.OP_DIVIDE:
+			left, right := vm.numberOperands()
+			vm.push(NumberValue(left / right))
+		case compiler.OP_NOT:
+			vm.push(BoolValue(isFalsey(vm.pop())))
+		case compiler.OP_NEGATE:
+			if !vm.peek(0).IsNumber() {
+				vm.runtimeError("Operand must be a number.")
+			}
+			vm.stack[vm.stackTop-1] = NumberValue(-vm.peek(0).AsNumber())
+		case compiler.OP_STRINGIFY:
+			if _, ok := vm.peek(0).AsObject().(*String); !ok {
+				// the value stays on the stack until its string is allocated
+				vm.stack[vm.stackTop-1] = ObjectValue(vm.internString(Stringify(vm.peek(0))))
+			}
+
+		case compiler.OP_PRINT:
+			fmt.Fprintln(vm.out, Stringify(vm.pop()))
+		case compiler.OP_JUMP:
+			offset := frame.readShort()
+			frame.ip += offset
+		case compiler.OP_JUMP_IF_FALSE:
+			offset := frame.readShort()
+			if isFalsey(vm.peek(0)) {
+				frame.ip += offset
+			}
+		case compiler.OP_LOOP:
+			offset := frame.readShort()
+			frame.ip -= offset
+		case compiler.OP_CALL:
+			argCount := int(frame.readByte())
+			vm.callValue(vm.peek(argCount), argCount)
+			frame = &vm.frames[vm.frameCount-1]
+		case compiler.OP_INVOKE:
+			name := frame.readString()
+			argCount := int(frame.readByte())
+			vm.invoke(name, argCount)
+			frame = &vm.frames[vm.frameCount-1]
+		case compiler.OP_SUPER_INVOKE:
+			name := frame.readString()
+			argCount := int(frame.readByte())
+			vm.invokeFromClass(vm.asClass(vm.pop()), name, argCount)
+			frame = &vm.frames[vm.frameCount-1]
+		case compiler.OP_CLOSURE:
+			function := frame.closure.Function.Chunk.Constants[frame.readShort()].(*compiler.Function)
+			closure := vm.newClosure(function)
+			vm.push(ObjectValue(closure))
+			for i := range closure.Upvalues {
+				isLocal := frame.readByte() == 1
+				index := int(frame.readByte())
+				if isLocal {
+					closure.Upvalues[i] = vm.captureUpvalue(frame.slots + index)
+				} else {
+					closure.Upvalues[i] = frame.closure.Upvalues[index]
+				}
+			}
+		case compiler.OP_CLOSE_UPVALUE:
+			vm.closeUpvalues(vm.stackTop - 1)
+			vm.pop()
+		case compiler.OP_RETURN:
+			result := vm.pop()
+			vm.closeUpvalues(frame.slots)
+			vm.frameCount--
+			if vm.frameCount == 0 {
+				vm.pop()
+				return
+			}
+			vm.stackTop = frame.slots
+			vm.push(result)
+			frame = &vm.frames[vm.frameCount-1]
+
+		case compiler.OP_CLASS:
+			vm.push(ObjectValue(vm.newClass(frame.readString())))
+		case compiler.OP_INHERIT:
+			superclass, ok := vm.peek(1).AsObject().(*Class)
+			if !ok {
+				vm.runtimeError("Superclass must be a class.")
+			}
+			// classes can't change after their declaration, so the methods are copied down once
+			subclass := vm.asClass(vm.peek(0))
+			for name, method := range superclass.Methods {
+				subclass.Methods[name] = method
+			}
+			vm.pop()
+		case compiler.OP_METHOD:
+			name := frame.readString()
+			method, ok := vm.peek(0).AsObject().(*Closure)
+			if !ok {
+				vm.runtimeError("Method must be a closure.")
+			}
+			vm.asClass(vm.peek(1)).Methods[keyOf(name)] = method
+			vm.pop()
+
+		default:
+			vm.runtimeError("Unknown opcode.")
+		}
+	}
+}
+
+// traceInstruction prints the current stack and the instruction, which is executed next
+func (vm *VM) traceInstruction(frame *callFrame) {
+	fmt.Fprint(vm.out, "          ")
+	for _, value := range vm.stack[:vm.stackTop] {
+		fmt.Fprint(vm.out, "[ "+Stringify(value)+" ]")
+	}
+	fmt.Fprintln(vm.out)
+	compiler.DisassembleInstruction(vm.out, &frame.closure.Function.Chunk, frame.ip)
+}
+
+func (vm *VM) numberOperands() (float64, float64) {
+	right, left := vm.peek(0), vm.peek(1)
+	if !left.IsNumber() || !right.IsNumber() {
+		vm.runtimeError("Operands must be numbers.")
+	}
+	vm.stackTop -= 2
+	return left.AsNumber(), right.AsNumber()
+}
+
+func (vm *VM) add() {
+	left, right := vm.peek(1), vm.peek(0)
+	if left.IsNumber() && right.IsNumber() {
+		vm.stackTop -= 2
+		vm.push(NumberValue(left.AsNumber() + right.AsNumber()))
+		return
+	}
+	leftString, lok := left.AsObject().(*String)
+	rightString, rok := right.AsObject().(*String)
+	if lok && rok {
+		// the operands stay on the stack until the result is allocated
+		result := vm.internString(leftString.Value + rightString.Value)
+		vm.stackTop -= 2
+		vm.push(ObjectValue(result))
+		return
+	}
+	vm.runtimeError("Operands must be two numbers or two strings.")
+}
+
+func (vm *VM) callValue(callee Value, argCount int) {
+	switch callee := callee.AsObject().(type) {
+	case *Closure:
+		vm.call(callee, argCount)
+	case *BoundMethod:
+		vm.stack[vm.stackTop-argCount-1] = callee.Receiver
+		vm.call(callee.Method, argCount)
+	case *Class:
+		vm.stack[vm.stackTop-argCount-1] = ObjectValue(vm.newInstance(callee))
+		if initializer, ok := callee.Methods[keyOf(vm.initString)]; ok {
+			vm.call(initializer, argCount)
+		} else if argCount != 0 {
+			vm.runtimeError(arityMessage(0, argCount))
+		}
+	case *Native:
+		if argCount != callee.Arity {
+			vm.runtimeError(arityMessage(callee.Arity, argCount))
+		}
+		arguments := make([]interface{}, argCount)
+		for i, argument := range vm.stack[vm.stackTop-argCount : vm.stackTop] {
+			arguments[i] = toNative(argument)
+		}
+		result, err := callee.Function(arguments)
+		if err != nil {
+			vm.runtimeError(err.Error())
+		}
+		value := vm.fromNative(result)
+		vm.stackTop -= argCount + 1
+		vm.push(value)
+	default:
+		vm.runtimeError("Can only call functions and classes.")
+	}
+}
+
+// call pushes a new frame. The callee and its arguments are already on the stack.
+func (vm *VM) call(closure *Closure, argCount int) {
+	if argCount != closure.Function.Arity {
+		vm.runtimeError(arityMessage(closure.Function.Arity, argCount))
+	}
+	if vm.frameCount == framesMax {
+		vm.runtimeError("Stack overflow.")
+	}
+	vm.frames[vm.frameCount] = callFrame{closure: closure, slots: vm.stackTop - argCount - 1}
+	vm.frameCount++
+}
+
+func arityMessage(arity, argCount int) string {
+	return "Expected " + strconv.Itoa(arity) + " arguments but got " + strconv.Itoa(argCount) + "."
+}
+
+// invoke calls a method of the receiver without creating a BoundMethod first.
+// Fields shadow methods, so a field holding a function is called instead.
+func (vm *VM) invoke(name *String, argCount int) {
+	instance, ok := vm.peek(argCount).AsObject().(*Instance)
+	if !ok {
+		vm.runtimeErrorAt(2, "Only instances have properties.")
+	}
+	if value, ok := instance.Fields[keyOf(name)]; ok {
+		vm.stack[vm.stackTop-argCount-1] = value
+		vm.callValue(value, argCount)
+		return
+	}
+	vm.invokeFromClass(instance.Class, name, argCount)
+}
+
+func (vm *VM) invokeFromClass(class *Class, name *String, argCount int) {
+	method, ok := class.Methods[keyOf(name)]
+	if !ok {
+		vm.runtimeErrorAt(2, "Undefined property '"+name.Value+"'.")
+	}
+	vm.call(method, argCount)
+}
+
+// bindMethod replaces the instance on top of the stack with its method
+func (vm *VM) bindMethod(class *Class, name *String) {
+	method, ok := class.Methods[keyOf(name)]
+	if !ok {
+		vm.runtimeError("Undefined property '" + name.Value + "'.")
+	}
+	bound := vm.newBoundMethod(vm.peek(0), method)
+	vm.pop()
+	vm.push(ObjectValue(bound))
+}
+
+// captureUpvalue reuses the open upvalue of the slot, so closures share captured variables.
+// The open upvalues are sorted by slot, the highest slot first.
+func (vm *VM) captureUpvalue(slot int) *Upvalue {
+	var previous *Upvalue
+	upvalue := vm.openUpvalues
+	for upvalue != nil && upvalue.slot > slot {
+		previous = upvalue
+		upvalue = upvalue.nextOpen
+	}
+	if upvalue != nil && upvalue.slot == slot {
+		return upvalue
+	}
+
+	created := vm.newUpvalue(slot)
+	created.nextOpen = upvalue
+	if previous == nil {
+		vm.openUpvalues = created
+	} else {
+		previous.nextOpen = created
+	}
+	return created
+}
+
+// closeUpvalues moves all variables at or above the slot off the stack
+func (vm *VM) closeUpvalues(last int) {
+	for vm.openUpvalues != nil && vm.openUpvalues.slot >= last {
+		upvalue := vm.openUpvalues
+		upvalue.closed = *upvalue.location
+		upvalue.location = &upvalue.closed
+		vm.openUpvalues = upvalue.nextOpen
+	}
+}
@@ -0,0 +1,149 @@
+package vm
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/th-lange/glox/compiler"
+)
+
+const propertyAccessScript = `class Particle {
+  init(positionX, positionY) {
+    this.positionX = positionX;
+    this.positionY = positionY;
+    this.velocityX = 1;
+    this.velocityY = 2;
+  }
+  step() {
+    this.positionX = this.positionX + this.velocityX;
+    this.positionY = this.positionY + this.velocityY;
+  }
+}
+var particle = Particle(0, 0);
+for (var i = 0; i < 10000; i = i + 1) {
+  particle.step();
+  particle.velocityX = particle.velocityY - particle.velocityX;
+}
+print particle.positionX;`
+
+func benchmarkScript(b *testing.B, source string) {
+	function, err := compiler.Compile(parseProgramForBenchmark(b, source))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vm := NewVM(ioutil.Discard)
+		if err := vm.Run(function); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+const globalAccessScript = `var counter = 0;
+var step = 1;
+var label = "count";
+for (var i = 0; i < 10000; i = i + 1) {
+  counter = counter + step;
+  if (label == "count") step = step + 0;
+}
+print counter;`
+
+// The script benchmarks measure the interned strings against the go strings, run them once as they are
+// and once with the interning turned off, then compare both runs:
+//
+//	go test -run '^$' -bench 'VM_(PropertyAccess|GlobalAccess)' -count 10 ./vm > interned.txt
+//	go test -run '^$' -bench 'VM_(PropertyAccess|GlobalAccess)' -count 10 -tags nointern ./vm > nointern.txt
+//	benchstat nointern.txt interned.txt
+func BenchmarkVM_PropertyAccess(b *testing.B) {
+	benchmarkScript(b, propertyAccessScript)
+}
+
+func BenchmarkVM_GlobalAccess(b *testing.B) {
+	benchmarkScript(b, globalAccessScript)
+}
+
+// The lookup benchmarks compare the cost of keying a table by the interned handle with keying it by the go string.
+var lookupNames = []string{"positionX", "positionY", "velocityX", "velocityY"}
+
+func BenchmarkLookup_InternedString(b *testing.B) {
+	vm := NewVM(ioutil.Discard)
+	fields := make(map[*String]interface{})
+	keys := make([]*String, len(lookupNames))
+	for i, name := range lookupNames {
+		keys[i] = vm.internString(name)
+		fields[keys[i]] = float64(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = fields[keys[i%len(keys)]]
+	}
+}
+
+func BenchmarkLookup_GoString(b *testing.B) {
+	fields := make(map[string]interface{})
+	for i, name := range lookupNames {
+		fields[name] = float64(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = fields[lookupNames[i%len(lookupNames)]]
+	}
+}
+
+const fibScript = `fun fib(n) {
+  if (n < 2) return n;
+  return fib(n - 2) + fib(n - 1);
+}
+print fib(30);`
+
+const loopScript = `var sum = 0;
+for (var i = 0; i < 1000000; i = i + 1) {
+  if (i / 2 > 1000) sum = sum + i * 0.5;
+  else sum = sum - 1;
+}
+print sum;`
+
+// The fib and loop benchmarks measure the tagged Value against values boxed into an interface{},
+// run them once as they are and once with the interfacevalues build tag, then compare both runs:
+//
+//	go test -run '^$' -bench 'VM_(Fib30|Loop)' -benchmem -count 10 ./vm > tagged.txt
+//	go test -run '^$' -bench 'VM_(Fib30|Loop)' -benchmem -count 10 -tags interfacevalues ./vm > interface.txt
+//	benchstat interface.txt tagged.txt
+func BenchmarkVM_Fib30(b *testing.B) {
+	benchmarkScript(b, fibScript)
+}
+
+func BenchmarkVM_Loop(b *testing.B) {
+	benchmarkScript(b, loopScript)
+}
+
+// The arithmetic benchmarks run the same push/add/pop sequence on a stack of Value
+// and on a stack of interface{}, the representation the vm used before.
+func BenchmarkArithmetic_Value(b *testing.B) {
+	stack := make([]Value, 2)
+	stack[0] = NumberValue(0)
+	for i := 0; i < b.N; i++ {
+		stack[1] = NumberValue(float64(i))
+		left, right := stack[0], stack[1]
+		if left.IsNumber() && right.IsNumber() {
+			stack[0] = NumberValue(left.AsNumber() + right.AsNumber())
+		}
+	}
+}
+
+func BenchmarkArithmetic_Interface(b *testing.B) {
+	stack := make([]interface{}, 2)
+	stack[0] = float64(0)
+	for i := 0; i < b.N; i++ {
+		stack[1] = float64(i)
+		left, lok := stack[0].(float64)
+		right, rok := stack[1].(float64)
+		if lok && rok {
+			stack[0] = left + right
+		}
+	}
+}
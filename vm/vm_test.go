@@ -0,0 +1,149 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/th-lange/glox/compiler"
+	"github.com/th-lange/glox/parser"
+	"github.com/th-lange/glox/scanner"
+	"github.com/th-lange/glox/statement"
+)
+
+func parseProgram(t *testing.T, source string) []statement.Stmt {
+	scnr := scanner.Scanner{}
+	scnr.Scan(source)
+	assert.False(t, scnr.HadError, "Expecting the source to be scanned without errors: "+source)
+	statements := parser.NewParser(&scnr.Tokens).Parse()
+	assert.NotNil(t, statements, "Expecting the source to be parsed without errors: "+source)
+	return statements
+}
+
+func parseProgramForBenchmark(b *testing.B, source string) []statement.Stmt {
+	scnr := scanner.Scanner{}
+	scnr.Scan(source)
+	statements := parser.NewParser(&scnr.Tokens).Parse()
+	if scnr.HadError || statements == nil {
+		b.Fatal("Expecting the benchmark source to be valid: " + source)
+	}
+	return statements
+}
+
+func TestVM_Interpret(t *testing.T) {
+	out := bytes.Buffer{}
+	vm := NewVM(&out)
+
+	err := vm.Interpret(parseProgram(t, "var a = 1;\nprint a + 2;\nprint \"a\" + \"b\";\nprint nil;"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "3\nab\nnil\n", out.String())
+	assert.Equal(t, 0, vm.stackTop, "Expecting an empty stack after the script returned.")
+}
+
+func TestVM_Interpret_SharedUpvalues(t *testing.T) {
+	out := bytes.Buffer{}
+	vm := NewVM(&out)
+
+	err := vm.Interpret(parseProgram(t, `var get;
+var set;
+fun pair() {
+  var value = "before";
+  fun getter() { print value; }
+  fun setter() { value = "after"; }
+  get = getter;
+  set = setter;
+}
+pair();
+get();
+set();
+get();`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "before\nafter\n", out.String(), "Expecting both closures to share the closed variable.")
+	assert.Nil(t, vm.openUpvalues, "Expecting all upvalues to be closed.")
+}
+
+func TestVM_Interpret_RuntimeError(t *testing.T) {
+	vm := NewVM(&bytes.Buffer{})
+
+	err := vm.Interpret(parseProgram(t, "fun f() {\n  return 1 + nil;\n}\nf();"), nil)
+	assert.EqualError(t, err, "[Line 2] RuntimeError (Column 12): Operands must be two numbers or two strings.")
+	assert.Equal(t, 0, vm.stackTop, "Expecting the stack to be reset.")
+	assert.Equal(t, 0, vm.frameCount, "Expecting the frames to be reset.")
+}
+
+func TestVM_Interpret_StackOverflow(t *testing.T) {
+	vm := NewVM(&bytes.Buffer{})
+
+	err := vm.Interpret(parseProgram(t, "fun recurse(n) { return recurse(n + 1); }\nrecurse(0);"), nil)
+	assert.EqualError(t, err, "[Line 1] RuntimeError (Column 38): Stack overflow.")
+}
+
+func TestVM_Run_MisplacedValues(t *testing.T) {
+	lines := []compiler.LineStart{{Offset: 0, Line: 1}}
+	tests := []struct {
+		name    string
+		code    []byte
+		message string
+	}{
+		{"Super of a non class", []byte{byte(compiler.OP_NIL), byte(compiler.OP_NIL), byte(compiler.OP_GET_SUPER), 0, 0, byte(compiler.OP_RETURN)}, "Expected a class."},
+		{"Method of a non class", []byte{byte(compiler.OP_NIL), byte(compiler.OP_CLOSURE), 0, 1, byte(compiler.OP_METHOD), 0, 0, byte(compiler.OP_RETURN)}, "Expected a class."},
+		{"Method is no closure", []byte{byte(compiler.OP_CLASS), 0, 0, byte(compiler.OP_NIL), byte(compiler.OP_METHOD), 0, 0, byte(compiler.OP_RETURN)}, "Method must be a closure."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := &compiler.Function{Name: "method", Chunk: compiler.Chunk{Code: []byte{byte(compiler.OP_NIL), byte(compiler.OP_RETURN)}, Lines: lines}}
+			function := &compiler.Function{Chunk: compiler.Chunk{Code: tt.code, Constants: []interface{}{"name", method}, Lines: lines}}
+
+			err := NewVM(&bytes.Buffer{}).Run(function)
+			assert.EqualError(t, err, "[Line 1] RuntimeError: "+tt.message, "Expecting broken bytecode to be reported instead of crashing the vm.")
+		})
+	}
+}
+
+func TestVM_RegisterNative(t *testing.T) {
+	out := bytes.Buffer{}
+	vm := NewVM(&out)
+	vm.RegisterNative("double", 1, func(arguments []interface{}) (interface{}, error) {
+		number, ok := arguments[0].(float64)
+		if !ok {
+			return nil, errors.New("double expects a number.")
+		}
+		return number * 2, nil
+	})
+
+	assert.NoError(t, vm.Interpret(parseProgram(t, "print double(21);\nprint double;"), nil))
+	assert.Equal(t, "42\n<native fn double>\n", out.String())
+
+	err := vm.Interpret(parseProgram(t, "\ndouble(\"text\");"), nil)
+	assert.EqualError(t, err, "[Line 2] RuntimeError (Column 14): double expects a number.")
+}
+
+func TestVM_Interpret_Trace(t *testing.T) {
+	out := bytes.Buffer{}
+	vm := NewVM(&out)
+	vm.Debug = 2
+
+	assert.NoError(t, vm.Interpret(parseProgram(t, "print -1;"), nil))
+	expected := `== <script> ==
+0000    1 OP_CONSTANT         0 '1'
+0003    | OP_NEGATE
+0004    | OP_PRINT
+0005    | OP_NIL
+0006    | OP_RETURN
+          [ <script> ]
+0000    1 OP_CONSTANT         0 '1'
+          [ <script> ][ 1 ]
+0003    | OP_NEGATE
+          [ <script> ][ -1 ]
+0004    | OP_PRINT
+-1
+          [ <script> ]
+0005    | OP_NIL
+          [ <script> ][ nil ]
+0006    | OP_RETURN
+`
+	assert.Equal(t, expected, out.String(), "Expecting the bytecode dump followed by the stack before every instruction.")
+}